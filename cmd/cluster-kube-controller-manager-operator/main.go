@@ -12,8 +12,10 @@ import (
 	"github.com/openshift/library-go/pkg/operator/staticpod/installerpod"
 	"github.com/openshift/library-go/pkg/operator/staticpod/prune"
 
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/cmd/explainconfig"
 	operatorcmd "github.com/openshift/cluster-kube-controller-manager-operator/pkg/cmd/operator"
 	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/cmd/recoverycontroller"
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/cmd/regeneratecerts"
 	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/cmd/render"
 	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/cmd/resourcegraph"
 	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator"
@@ -42,6 +44,8 @@ func NewSSCSCommand(ctx context.Context) *cobra.Command {
 	cmd.AddCommand(resourcegraph.NewResourceChainCommand())
 	cmd.AddCommand(certsyncpod.NewCertSyncControllerCommand(operator.CertConfigMaps, operator.CertSecrets))
 	cmd.AddCommand(recoverycontroller.NewCertRecoveryControllerCommand(ctx))
+	cmd.AddCommand(regeneratecerts.NewRegenerateCertificatesCommand(ctx))
+	cmd.AddCommand(explainconfig.NewExplainConfigCommand(ctx))
 
 	return cmd
 }
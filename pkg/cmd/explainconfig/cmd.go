@@ -0,0 +1,125 @@
+package explainconfig
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
+
+	operatorv1client "github.com/openshift/client-go/operator/clientset/versioned/typed/operator/v1"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/targetconfigcontroller"
+)
+
+type explainConfigOpts struct {
+	kubeconfig string
+	flags      []string
+	output     string
+}
+
+// NewExplainConfigCommand creates a command printing which config layer set each kube-controller-manager flag.
+func NewExplainConfigCommand(ctx context.Context) *cobra.Command {
+	o := &explainConfigOpts{output: "table"}
+
+	cmd := &cobra.Command{
+		Use:   "explain-config",
+		Short: "Explain which config layer set each kube-controller-manager flag",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := o.Validate(); err != nil {
+				klog.Fatal(err)
+			}
+			if err := o.Run(ctx, os.Stdout); err != nil {
+				klog.Fatal(err)
+			}
+		},
+	}
+
+	o.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+func (o *explainConfigOpts) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.kubeconfig, "kubeconfig", o.kubeconfig, "Path to the kubeconfig file. Uses the in-cluster config if empty.")
+	fs.StringSliceVar(&o.flags, "flag", o.flags, "Only explain the given kube-controller-manager flags, without leading dashes.")
+	fs.StringVarP(&o.output, "output", "o", o.output, "Output format, one of: table, yaml.")
+}
+
+// Validate verifies the inputs.
+func (o *explainConfigOpts) Validate() error {
+	if o.output != "table" && o.output != "yaml" {
+		return fmt.Errorf("unsupported output format %q, must be table or yaml", o.output)
+	}
+	return nil
+}
+
+func (o *explainConfigOpts) Run(ctx context.Context, out io.Writer) error {
+	clientConfig, err := clientcmd.BuildConfigFromFlags("", o.kubeconfig)
+	if err != nil {
+		return err
+	}
+	kubeClient, err := kubernetes.NewForConfig(clientConfig)
+	if err != nil {
+		return err
+	}
+	operatorClient, err := operatorv1client.NewForConfig(clientConfig)
+	if err != nil {
+		return err
+	}
+
+	operator, err := operatorClient.KubeControllerManagers().Get(ctx, "cluster", metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	provenance, err := targetconfigcontroller.ExplainKubeControllerManagerConfig(ctx, kubeClient.CoreV1(), &operator.Spec.StaticPodOperatorSpec)
+	if err != nil {
+		return err
+	}
+
+	if len(o.flags) > 0 {
+		flags := []targetconfigcontroller.FlagProvenance{}
+		for _, flag := range provenance.Flags {
+			for _, name := range o.flags {
+				if flag.Name == strings.TrimLeft(name, "-") {
+					flags = append(flags, flag)
+					break
+				}
+			}
+		}
+		provenance.Flags = flags
+	}
+
+	return printProvenance(out, o.output, provenance)
+}
+
+func printProvenance(out io.Writer, output string, provenance *targetconfigcontroller.ConfigProvenance) error {
+	if output == "yaml" {
+		data, err := yaml.Marshal(provenance)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(data)
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "FLAG\tVALUE\tLAYER\tOVERRIDES")
+	for _, flag := range provenance.Flags {
+		overridden := []string{}
+		for _, layer := range flag.Overridden {
+			overridden = append(overridden, fmt.Sprintf("%s=%s", layer.Layer, strings.Join(layer.Values, ",")))
+		}
+		fmt.Fprintf(w, "--%s\t%s\t%s\t%s\n", flag.Name, strings.Join(flag.Values, ","), flag.Layer, strings.Join(overridden, "; "))
+	}
+	return w.Flush()
+}
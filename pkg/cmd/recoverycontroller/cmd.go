@@ -3,6 +3,7 @@ package recoverycontroller
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
@@ -18,6 +19,8 @@ import (
 	"github.com/openshift/library-go/pkg/operator/status"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	genericapiserver "k8s.io/apiserver/pkg/server"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/clock"
@@ -25,6 +28,16 @@ import (
 
 type Options struct {
 	controllerContext *controllercmd.ControllerContext
+
+	// listenFlag and kubeConfigFlag are registered by controllercmd
+	listenFlag     *pflag.Flag
+	kubeConfigFlag *pflag.Flag
+
+	bindAddress     string
+	kubeConfigFile  string
+	clientCAFile    string
+	servingCertFile string
+	servingKeyFile  string
 }
 
 func NewCertRecoveryControllerCommand(ctx context.Context) *cobra.Command {
@@ -52,24 +65,42 @@ func NewCertRecoveryControllerCommand(ctx context.Context) *cobra.Command {
 		return nil
 	}, c)
 
-	// Disable serving for recovery as it introduces a dependency on kube-system::extension-apiserver-authentication
-	// configmap which prevents it to start as the CA bundle is expired.
-	// TODO: Remove when the internal logic can start serving without extension-apiserver-authentication
-	//  	 and live reload extension-apiserver-authentication after it is available
+	// Disable the controllercmd serving for recovery as it introduces a dependency on kube-system::extension-apiserver-authentication
+	// configmap which prevents it to start as the CA bundle is expired. The recovery controller serves on --listen itself,
+	// authenticating with the client CA on disk.
 	ccc.DisableServing = true
 
 	cmd := ccc.NewCommandWithContext(ctx)
 	cmd.Use = "cert-recovery-controller"
 	cmd.Short = "Start the Cluster Certificate Recovery Controller"
 
+	o.AddFlags(cmd.Flags())
+
 	return cmd
 }
 
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	o.listenFlag = fs.Lookup("listen")
+	o.kubeConfigFlag = fs.Lookup("kubeconfig")
+	fs.StringVar(&o.clientCAFile, "client-ca-file", defaultClientCAFile, "The CA bundle verifying the client certificates of requests to /metrics. It is reloaded when it changes.")
+	fs.StringVar(&o.servingCertFile, "tls-cert-file", defaultServingCertFile, "The serving certificate. A self-signed certificate is generated when it does not exist.")
+	fs.StringVar(&o.servingKeyFile, "tls-private-key-file", defaultServingKeyFile, "The serving certificate key.")
+}
+
 func (o *Options) Validate(ctx context.Context) error {
+	if o.listenFlag != nil && len(o.listenFlag.Value.String()) > 0 && len(o.clientCAFile) == 0 {
+		return fmt.Errorf("--client-ca-file is required to serve on --listen")
+	}
 	return nil
 }
 
 func (o *Options) Complete(ctx context.Context) error {
+	if o.listenFlag != nil {
+		o.bindAddress = o.listenFlag.Value.String()
+	}
+	if o.kubeConfigFlag != nil {
+		o.kubeConfigFile = o.kubeConfigFlag.Value.String()
+	}
 	return nil
 }
 
@@ -148,22 +179,48 @@ func (o *Options) Run(ctx context.Context, clock clock.Clock) error {
 		return err
 	}
 
+	var server *genericapiserver.GenericAPIServer
+	if len(o.bindAddress) > 0 {
+		serverConfig, err := o.newServerConfig()
+		if err != nil {
+			return err
+		}
+		server, err = serverConfig.Complete(nil).New("cert-recovery-controller", genericapiserver.NewEmptyDelegate())
+		if err != nil {
+			return err
+		}
+	}
+
 	// We can't start informers until after the resources have been requested. Now is the time.
 	kubeInformersForNamespaces.Start(ctx.Done())
 	dynamicInformers.Start(ctx.Done())
 	configInformers.Start(ctx.Done())
 
-	// FIXME: These are missing a wait group to track goroutines and handle graceful termination
-	// (@deads2k wants time to think it through)
+	var wg sync.WaitGroup
+	wg.Add(2)
 	go func() {
+		defer wg.Done()
 		certRotationController.Run(ctx, 1)
 	}()
 
 	go func() {
+		defer wg.Done()
 		csrController.Run(ctx)
 	}()
 
+	if server != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := server.PrepareRun().RunWithContext(ctx); err != nil {
+				klog.Fatal(err)
+			}
+			klog.Info("server exited")
+		}()
+	}
+
 	<-ctx.Done()
+	wg.Wait()
 
 	return nil
 }
@@ -3,6 +3,7 @@ package recoverycontroller
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -40,6 +41,10 @@ type CSRController struct {
 	queue workqueue.RateLimitingInterface
 
 	resourceSyncController *resourcesynccontroller.ResourceSyncController
+
+	// csrSignerLease is shared with the operator and the cert-recovery-controllers of the other masters, the CSR
+	// signer is only managed while it is held
+	csrSignerLease *targetconfigcontroller.CSRSignerLease
 }
 
 func NewCSRController(
@@ -85,11 +90,17 @@ func NewCSRController(
 		v1helpers.CachedConfigMapGetter(kubeClient.CoreV1(), kubeInformersForNamespaces),
 		c.eventRecorder,
 	)
-	err := operatorresourcesync.AddSyncCSRControllerCA(c.resourceSyncController)
+	var err error
+	c.csrSignerLease, err = targetconfigcontroller.NewCSRSignerLease(kubeClient, "cert-recovery-controller", func() { c.queue.Add(workQueueKey) })
+	if err != nil {
+		return nil, err
+	}
+	// the rules are only synced while the lease is held, the resource sync resyncs every minute
+	err = operatorresourcesync.AddSyncCSRControllerCA(c.resourceSyncController, operatorClient, kubeInformersForNamespaces, c.csrSignerLease.IsLeader)
 	if err != nil {
 		return nil, err
 	}
-	err = operatorresourcesync.AddSyncClientCertKeySecret(c.resourceSyncController)
+	err = operatorresourcesync.AddSyncClientCertKeySecret(c.resourceSyncController, operatorClient, kubeInformersForNamespaces, c.csrSignerLease.IsLeader)
 	if err != nil {
 		return nil, err
 	}
@@ -101,9 +112,11 @@ func (c *CSRController) Run(ctx context.Context) {
 	defer utilruntime.HandleCrash()
 
 	klog.Info("Starting CSR controller")
+	var wg sync.WaitGroup
 	defer func() {
 		klog.Info("Shutting down CSR controller")
 		c.queue.ShutDown()
+		wg.Wait()
 		klog.Info("CSR controller shut down")
 	}()
 
@@ -111,17 +124,23 @@ func (c *CSRController) Run(ctx context.Context) {
 		return
 	}
 
-	// FIXME: These are missing a wait group to track goroutines and handle graceful termination
-	// (@deads2k wants time to think it through)
-
+	wg.Add(3)
 	go func() {
+		defer wg.Done()
 		wait.UntilWithContext(ctx, c.runWorker, time.Second)
 	}()
 
 	go func() {
+		defer wg.Done()
 		c.resourceSyncController.Run(ctx, 1)
 	}()
 
+	// the lease is released on shutdown so that another actor takes over without waiting for it to expire
+	go func() {
+		defer wg.Done()
+		c.csrSignerLease.Run(ctx)
+	}()
+
 	<-ctx.Done()
 }
 
@@ -151,18 +170,16 @@ func (c *CSRController) processNextItem(ctx context.Context) bool {
 }
 
 func (c *CSRController) sync(ctx context.Context) error {
-	klog.V(4).Infof("Starting CSRController sync")
-	defer klog.V(4).Infof("CSRController sync done")
-
-	// Always start 10 seconds later after a change occurred. Makes us less likely to steal work and logs from the operator.
-	timer := time.NewTimer(10 * time.Second)
-	defer timer.Stop()
-	select {
-	case <-timer.C:
-	case <-ctx.Done():
+	// the operator or another cert-recovery-controller manages the CSR signer, the queue is filled again once the lease
+	// is acquired
+	if !c.csrSignerLease.IsLeader() {
+		klog.V(4).Infof("Skipping CSRController sync, lease %s is held by another actor", targetconfigcontroller.CSRSignerLeaseName)
 		return nil
 	}
 
+	klog.V(4).Infof("Starting CSRController sync")
+	defer klog.V(4).Infof("CSRController sync done")
+
 	_, changed, err := targetconfigcontroller.ManageCSRIntermediateCABundle(ctx, c.secretLister, c.kubeClient.CoreV1(), c.eventRecorder)
 	if err != nil {
 		return err
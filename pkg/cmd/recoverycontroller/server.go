@@ -0,0 +1,102 @@
+package recoverycontroller
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apiserver/pkg/authorization/union"
+	genericapiserver "k8s.io/apiserver/pkg/server"
+	genericapiserveroptions "k8s.io/apiserver/pkg/server/options"
+	"k8s.io/apiserver/pkg/util/compatibility"
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/authorization/hardcodedauthorizer"
+)
+
+const (
+	// defaultClientCAFile is the client CA synced to disk by the cert-syncer, it stays usable while the certificates
+	// in kube-system/extension-apiserver-authentication are expired.
+	defaultClientCAFile = "/etc/kubernetes/static-pod-certs/configmaps/client-ca/ca-bundle.crt"
+
+	defaultServingCertFile = "/etc/kubernetes/static-pod-resources/secrets/serving-cert/tls.crt"
+	defaultServingKeyFile  = "/etc/kubernetes/static-pod-resources/secrets/serving-cert/tls.key"
+)
+
+// newServerConfig returns the config of the server exposing /healthz, /readyz and /metrics of the recovery controller.
+// Unlike the library-go server, client certificates are verified with the client CA file, which is reloaded when it
+// changes, instead of kube-system/extension-apiserver-authentication. Health checks are always allowed, /metrics is
+// allowed for the monitoring stack without a SubjectAccessReview.
+func (o *Options) newServerConfig() (*genericapiserver.Config, error) {
+	scheme := runtime.NewScheme()
+	metav1.AddToGroupVersion(scheme, metav1.SchemeGroupVersion)
+	config := genericapiserver.NewConfig(serializer.NewCodecFactory(scheme))
+
+	host, portString, err := net.SplitHostPort(o.bindAddress)
+	if err != nil {
+		return nil, fmt.Errorf("listen address %q is invalid: %w", o.bindAddress, err)
+	}
+	port, err := strconv.Atoi(portString)
+	if err != nil {
+		return nil, fmt.Errorf("listen address %q is invalid: %w", o.bindAddress, err)
+	}
+	bindIP := net.ParseIP(host)
+	if bindIP == nil {
+		return nil, fmt.Errorf("listen address %q is invalid: not an IP", o.bindAddress)
+	}
+
+	servingOptions := genericapiserveroptions.NewSecureServingOptions()
+	servingOptions.BindAddress = bindIP
+	servingOptions.BindPort = port
+	// the root filesystem of the container is read-only, keep a generated certificate in memory
+	servingOptions.ServerCert.CertDirectory = ""
+	// the serving-cert is optional in the revision, it is not there until the service-ca controller is up
+	if canRead, err := certutil.CanReadCertAndKey(o.servingCertFile, o.servingKeyFile); err != nil {
+		klog.Warningf("Using an in-memory self-signed serving certificate: %v", err)
+	} else if !canRead {
+		klog.Warningf("Using an in-memory self-signed serving certificate, %s and %s do not exist", o.servingCertFile, o.servingKeyFile)
+	} else {
+		servingOptions.ServerCert.CertKey.CertFile = o.servingCertFile
+		servingOptions.ServerCert.CertKey.KeyFile = o.servingKeyFile
+	}
+	if err := servingOptions.MaybeDefaultWithSelfSignedCerts("localhost", nil, nil); err != nil {
+		return nil, err
+	}
+	if err := servingOptions.WithLoopback().ApplyTo(&config.SecureServing, &config.LoopbackClientConfig); err != nil {
+		return nil, err
+	}
+
+	authenticationOptions := genericapiserveroptions.NewDelegatingAuthenticationOptions()
+	authenticationOptions.ClientCert.ClientCA = o.clientCAFile
+	authenticationOptions.SkipInClusterLookup = true
+	authenticationOptions.RemoteKubeConfigFile = o.kubeConfigFile
+	// the platform generally uses 30s for /metrics scraping, avoid API request for every other /metrics request to the component
+	authenticationOptions.CacheTTL = 35 * time.Second
+	if err := authenticationOptions.ApplyTo(&config.Authentication, config.SecureServing, config.OpenAPIConfig); err != nil {
+		return nil, fmt.Errorf("error initializing client certificate authentication: %w", err)
+	}
+
+	authorizationOptions := genericapiserveroptions.NewDelegatingAuthorizationOptions().
+		WithAlwaysAllowPaths("/healthz", "/readyz", "/livez").
+		WithAlwaysAllowGroups("system:masters")
+	authorizationOptions.RemoteKubeConfigFile = o.kubeConfigFile
+	authorizationOptions.AllowCacheTTL = 35 * time.Second
+	if err := authorizationOptions.ApplyTo(&config.Authorization); err != nil {
+		return nil, fmt.Errorf("error initializing delegating authorization: %w", err)
+	}
+	config.Authorization.Authorizer = union.New(
+		// metrics scraping must work while the kube-apiserver cannot answer SubjectAccessReviews
+		hardcodedauthorizer.NewHardCodedMetricsAuthorizer(),
+		config.Authorization.Authorizer,
+	)
+
+	config.SecureServing.DisableHTTP2 = true
+	config.EffectiveVersion = compatibility.DefaultBuildEffectiveVersion()
+
+	return config, nil
+}
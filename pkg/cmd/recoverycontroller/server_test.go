@@ -0,0 +1,72 @@
+package recoverycontroller
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/openshift/library-go/pkg/crypto"
+)
+
+func TestNewServerConfigWithoutServingCert(t *testing.T) {
+	dir := t.TempDir()
+	clientCA, err := crypto.MakeSelfSignedCAConfigForDuration("client-ca", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCAFile := filepath.Join(dir, "ca-bundle.crt")
+	if err := clientCA.WriteCertConfigFile(clientCAFile, filepath.Join(dir, "ca.key")); err != nil {
+		t.Fatal(err)
+	}
+	// the API is not reachable in recovery
+	kubeConfigFile := filepath.Join(dir, "kubeconfig")
+	if err := clientcmd.WriteToFile(clientcmdapi.Config{
+		Clusters:       map[string]*clientcmdapi.Cluster{"recovery": {Server: "https://127.0.0.1:1"}},
+		AuthInfos:      map[string]*clientcmdapi.AuthInfo{"recovery": {}},
+		Contexts:       map[string]*clientcmdapi.Context{"recovery": {Cluster: "recovery", AuthInfo: "recovery"}},
+		CurrentContext: "recovery",
+	}, kubeConfigFile); err != nil {
+		t.Fatal(err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bindAddress := listener.Addr().String()
+	if err := listener.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the serving cert is missing and the working directory stands in for the read-only root filesystem
+	workDir := t.TempDir()
+	t.Chdir(workDir)
+	o := &Options{
+		bindAddress:     bindAddress,
+		kubeConfigFile:  kubeConfigFile,
+		clientCAFile:    clientCAFile,
+		servingCertFile: filepath.Join(dir, "serving-cert", "tls.crt"),
+		servingKeyFile:  filepath.Join(dir, "serving-cert", "tls.key"),
+	}
+	config, err := o.newServerConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.SecureServing.Cert == nil {
+		t.Errorf("expected a generated serving certificate")
+	}
+	if config.SecureServing.Listener != nil {
+		config.SecureServing.Listener.Close()
+	}
+	entries, err := os.ReadDir(workDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		t.Errorf("expected nothing to be written, found %s", entry.Name())
+	}
+}
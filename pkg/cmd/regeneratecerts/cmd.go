@@ -0,0 +1,277 @@
+package regeneratecerts
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/client-go/util/cert"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/crypto"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/targetconfigcontroller"
+)
+
+type regenerateCertsOpts struct {
+	staticPodResourcesDir string
+	signerValidity        time.Duration
+	force                 bool
+	dryRun                bool
+}
+
+// NewRegenerateCertificatesCommand creates a command regenerating the CSR signer of the kube-controller-manager on a
+// master, without the API. It is meant for clusters whose certificates expired while they were powered off.
+func NewRegenerateCertificatesCommand(ctx context.Context) *cobra.Command {
+	o := &regenerateCertsOpts{
+		staticPodResourcesDir: "/etc/kubernetes/static-pod-resources",
+		// the csr-signer validity without the kube-controller-manager-csr-signing configmap
+		signerValidity: 30 * 24 * time.Hour,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "regenerate-certificates",
+		Short: "Regenerate the CSR signer of the kube-controller-manager on disk, without the API",
+		Long: `Regenerate the CSR signer of the kube-controller-manager on disk, without the API.
+
+The csr-signer in the cert dir of the kube-controller-manager static pod is regenerated as a self-signed CA when it is
+missing, invalid or expired. The csr-signer is added to the client-ca bundle in the cert dir of the kube-apiserver static
+pod, which reloads it, so that the client certificates it issues are trusted.
+
+Once the API answers again, the cert-syncers replace both files with the in-cluster copies. Client certificates issued
+with the regenerated csr-signer are no longer trusted then and must be issued again.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := o.Validate(); err != nil {
+				klog.Fatal(err)
+			}
+			if err := o.Run(ctx, os.Stdout); err != nil {
+				klog.Fatal(err)
+			}
+		},
+	}
+
+	o.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+func (o *regenerateCertsOpts) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.staticPodResourcesDir, "static-pod-resources-dir", o.staticPodResourcesDir, "The directory of the static pod resources on the master.")
+	fs.DurationVar(&o.signerValidity, "signer-validity", o.signerValidity, "The validity of a new csr-signer.")
+	fs.BoolVar(&o.force, "force", o.force, "Regenerate the csr-signer even when it is not expired.")
+	fs.BoolVar(&o.dryRun, "dry-run", o.dryRun, "Report the changes without writing them.")
+}
+
+// Validate verifies the inputs.
+func (o *regenerateCertsOpts) Validate() error {
+	if len(o.staticPodResourcesDir) == 0 {
+		return fmt.Errorf("--static-pod-resources-dir is required")
+	}
+	if o.signerValidity < time.Hour {
+		return fmt.Errorf("--signer-validity must be at least 1h, got %v", o.signerValidity)
+	}
+	return nil
+}
+
+func (o *regenerateCertsOpts) Run(ctx context.Context, out io.Writer) error {
+	files, err := o.regenerate(o.staticPodResourcesDir, time.Now())
+	if err != nil {
+		return err
+	}
+	changes, err := applyFiles(files, o.dryRun)
+	if err != nil {
+		return err
+	}
+	return printChanges(out, changes, o.dryRun)
+}
+
+// file is the content a file in the cert dir must have.
+type file struct {
+	path    string
+	content []byte
+	perm    os.FileMode
+	detail  string
+}
+
+// change reports what was done to a file.
+type change struct {
+	path   string
+	action string
+	detail string
+}
+
+// regenerate returns the files to write in the static pod resources dir.
+func (o *regenerateCertsOpts) regenerate(resourcesDir string, now time.Time) ([]file, error) {
+	certDir := filepath.Join(resourcesDir, "kube-controller-manager-certs")
+	if _, err := os.Stat(certDir); err != nil {
+		return nil, fmt.Errorf("the kube-controller-manager cert dir is not usable: %w", err)
+	}
+	// the kube-apiserver verifies client certificates with the bundle the kube-apiserver-operator syncs to this path
+	clientCAPath := filepath.Join(resourcesDir, "kube-apiserver-certs", "configmaps", "client-ca", "ca-bundle.crt")
+	clientCA, err := readBundle(clientCAPath)
+	if err != nil {
+		return nil, err
+	}
+
+	csrSignerDir := filepath.Join(certDir, "secrets", "csr-signer")
+	files := []file{}
+
+	csrSigner, err := readCA(csrSignerDir)
+	reason := ""
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		reason = "the csr-signer is missing"
+	case err != nil:
+		reason = fmt.Sprintf("the csr-signer is invalid: %v", err)
+	case !now.Before(csrSigner.Config.Certs[0].NotAfter):
+		reason = fmt.Sprintf("the csr-signer expired at %s", csrSigner.Config.Certs[0].NotAfter.Format(time.RFC3339))
+	case o.force:
+		reason = "forced"
+	}
+
+	if len(reason) > 0 {
+		// the csr-signer-signer only exists in the API, the csr-signer is self-signed. It is named like the csr-signer
+		// the operator rotates.
+		csrSignerConfig, err := crypto.MakeSelfSignedCAConfigForDuration(fmt.Sprintf("%s_@%d", "kube-csr-signer", now.Unix()), o.signerValidity)
+		if err != nil {
+			return nil, err
+		}
+		csrSigner = &crypto.CA{Config: csrSignerConfig, SerialGenerator: &crypto.RandomSerialGenerator{}}
+		csrSignerFiles, err := certKeyFiles(csrSignerDir, csrSignerConfig, "new csr-signer, "+reason)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, csrSignerFiles...)
+	}
+
+	// the CSR signing controller only uses the first cert of the csr-signer
+	content, err := targetconfigcontroller.CombineCABundle(append(clientCA, csrSigner.Config.Certs[0])...)
+	if err != nil {
+		return nil, err
+	}
+	certificates, _ := cert.ParseCertsPEM(content)
+	files = append(files, file{
+		path:    clientCAPath,
+		content: content,
+		perm:    0644,
+		detail:  fmt.Sprintf("%d certificates, trusting the csr-signer", len(certificates)),
+	})
+
+	return files, nil
+}
+
+func readCA(dir string) (*crypto.CA, error) {
+	certBytes, err := os.ReadFile(filepath.Join(dir, "tls.crt"))
+	if err != nil {
+		return nil, err
+	}
+	keyBytes, err := os.ReadFile(filepath.Join(dir, "tls.key"))
+	if err != nil {
+		return nil, err
+	}
+	return crypto.GetCAFromBytes(certBytes, keyBytes)
+}
+
+// readBundle returns the certificates of the CA bundle on disk.
+func readBundle(path string) ([]*x509.Certificate, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("the kube-apiserver client-ca is not usable: %w", err)
+	}
+	certificates, err := cert.ParseCertsPEM(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s is malformed: %w", path, err)
+	}
+	return certificates, nil
+}
+
+func certKeyFiles(dir string, config *crypto.TLSCertificateConfig, detail string) ([]file, error) {
+	certBytes, keyBytes, err := config.GetPEMBytes()
+	if err != nil {
+		return nil, err
+	}
+	notAfter := config.Certs[0].NotAfter.Format(time.RFC3339)
+	return []file{
+		{path: filepath.Join(dir, "tls.crt"), content: certBytes, perm: 0644, detail: fmt.Sprintf("%s, %s, expires %s", detail, config.Certs[0].Subject.CommonName, notAfter)},
+		{path: filepath.Join(dir, "tls.key"), content: keyBytes, perm: 0600, detail: detail},
+	}, nil
+}
+
+// applyFiles writes the files that changed, unless it is a dry run, and reports the changes.
+func applyFiles(files []file, dryRun bool) ([]change, error) {
+	changes := []change{}
+	for _, f := range files {
+		existing, err := os.ReadFile(f.path)
+		action := "updated"
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			action = "created"
+		case err != nil:
+			return nil, err
+		case bytes.Equal(existing, f.content):
+			changes = append(changes, change{path: f.path, action: "unchanged", detail: f.detail})
+			continue
+		}
+
+		if !dryRun {
+			if err := writeFile(f.path, f.content, f.perm); err != nil {
+				return nil, err
+			}
+		}
+		changes = append(changes, change{path: f.path, action: action, detail: f.detail})
+	}
+	return changes, nil
+}
+
+// writeFile replaces the file atomically, the kube-controller-manager may read it at any time.
+func writeFile(path string, content []byte, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func printChanges(out io.Writer, changes []change, dryRun bool) error {
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "FILE\tACTION\tDETAIL")
+	for _, c := range changes {
+		action := c.action
+		if dryRun && action != "unchanged" {
+			action = "would be " + action
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", c.path, action, c.detail)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if dryRun {
+		_, err := fmt.Fprintln(out, "Dry run, nothing was written.")
+		return err
+	}
+	return nil
+}
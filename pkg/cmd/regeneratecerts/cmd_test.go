@@ -0,0 +1,124 @@
+package regeneratecerts
+
+import (
+	"bytes"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/util/cert"
+
+	"github.com/openshift/library-go/pkg/crypto"
+)
+
+func TestRegenerate(t *testing.T) {
+	o := &regenerateCertsOpts{signerValidity: 30 * 24 * time.Hour}
+	resourcesDir := t.TempDir()
+	csrSignerCert := filepath.Join(resourcesDir, "kube-controller-manager-certs", "secrets", "csr-signer", "tls.crt")
+	clientCA := filepath.Join(resourcesDir, "kube-apiserver-certs", "configmaps", "client-ca", "ca-bundle.crt")
+
+	// a master only has the kube-apiserver client-ca, the csr-signer is missing
+	if err := os.MkdirAll(filepath.Join(resourcesDir, "kube-controller-manager-certs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := o.regenerate(resourcesDir, time.Now()); err == nil {
+		t.Fatalf("expected an error without the kube-apiserver client-ca")
+	}
+	adminCA, err := crypto.MakeSelfSignedCAConfigForDuration("admin-kubeconfig-signer", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	adminCABytes, _, err := adminCA.GetPEMBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFile(clientCA, adminCABytes, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(now time.Time, dryRun bool) map[string]string {
+		t.Helper()
+		files, err := o.regenerate(resourcesDir, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		changes, err := applyFiles(files, dryRun)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := printChanges(&bytes.Buffer{}, changes, dryRun); err != nil {
+			t.Fatal(err)
+		}
+		actions := map[string]string{}
+		for _, c := range changes {
+			actions[c.path] = c.action
+		}
+		return actions
+	}
+	read := func(path string) []*x509.Certificate {
+		t.Helper()
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		certificates, err := cert.ParseCertsPEM(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return certificates
+	}
+	verify := func() *x509.Certificate {
+		t.Helper()
+		csrSigner := read(csrSignerCert)
+		if len(csrSigner) != 1 {
+			t.Fatalf("expected a single csr-signer certificate, got %d", len(csrSigner))
+		}
+		roots := x509.NewCertPool()
+		trustsAdmin := false
+		for _, c := range read(clientCA) {
+			roots.AddCert(c)
+			trustsAdmin = trustsAdmin || c.Equal(adminCA.Certs[0])
+		}
+		if _, err := csrSigner[0].Verify(x509.VerifyOptions{Roots: roots}); err != nil {
+			t.Errorf("csr-signer is not trusted by the kube-apiserver client-ca: %v", err)
+		}
+		if !trustsAdmin {
+			t.Errorf("expected the kube-apiserver client-ca to keep its certificates")
+		}
+		return csrSigner[0]
+	}
+
+	actions := run(time.Now(), true)
+	if actions[csrSignerCert] != "created" || actions[clientCA] != "updated" {
+		t.Fatalf("expected the csr-signer to be created and trusted, got %v", actions)
+	}
+	if _, err := os.Stat(csrSignerCert); !os.IsNotExist(err) {
+		t.Fatalf("expected a dry run to write nothing, got %v", err)
+	}
+
+	actions = run(time.Now(), false)
+	if actions[csrSignerCert] != "created" || actions[clientCA] != "updated" {
+		t.Fatalf("expected the csr-signer to be created and trusted, got %v", actions)
+	}
+	firstSigner := verify()
+	if info, err := os.Stat(filepath.Join(filepath.Dir(csrSignerCert), "tls.key")); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("expected the key to be private, got %v %v", info.Mode(), err)
+	}
+
+	actions = run(time.Now(), false)
+	for path, action := range actions {
+		if action != "unchanged" {
+			t.Errorf("expected %s to be unchanged, got %s", path, action)
+		}
+	}
+
+	actions = run(time.Now().Add(31*24*time.Hour), false)
+	if actions[csrSignerCert] != "updated" || actions[clientCA] != "updated" {
+		t.Fatalf("expected the expired csr-signer to be replaced and trusted, got %v", actions)
+	}
+	if verify().Equal(firstSigner) {
+		t.Errorf("expected a new csr-signer")
+	}
+}
@@ -284,6 +284,9 @@ func (r *renderOpts) Run() error {
 	if err := yaml.Unmarshal(renderConfig.FileConfig.BootstrapConfig, &kubeControllerManagerConfig); err != nil {
 		return fmt.Errorf("failed to unmarshal the kube-controller-manager config: %w", err)
 	}
+	if err := targetconfigcontroller.ValidateKubeControllerManagerArgs(kubeControllerManagerConfig); err != nil {
+		return err
+	}
 	extendedArguments := targetconfigcontroller.GetKubeControllerManagerArgs(kubeControllerManagerConfig)
 	for _, arg := range extendedArguments {
 		renderConfig.ExtendedArguments += fmt.Sprintf("\n    - %s", arg)
@@ -162,13 +162,22 @@ func Resources() resourcegraph.Resources {
 		Note("Rotated").
 		From(managedCSRSignerSigner).
 		Add(ret)
+	userCSRSigner := resourcegraph.NewSecret(operatorclient.GlobalUserSpecifiedConfigNamespace, "kube-controller-manager-csr-signer").
+		Note("Static").
+		Add(ret)
+	validatedUserCSRSigner := resourcegraph.NewSecret(operatorclient.OperatorNamespace, "csr-signer-user").
+		Note("Validated").
+		From(userCSRSigner).
+		Add(ret)
 	strippedSigner := resourcegraph.NewSecret(operatorclient.TargetNamespace, "csr-signer").
 		Note("Reduced").
 		From(managedCSRSigner).
+		From(validatedUserCSRSigner).
 		Add(ret)
 	managedCSRSignerCA := resourcegraph.NewConfigMap(operatorclient.OperatorNamespace, "csr-signer-ca").
 		Note("Rotated").
 		From(managedCSRSigner).
+		From(validatedUserCSRSigner).
 		Add(ret)
 	operatorCSRCA := resourcegraph.NewConfigMap(operatorclient.OperatorNamespace, "csr-controller-ca").
 		Note("Unioned").
@@ -0,0 +1,165 @@
+package certmetrics
+
+import (
+	"crypto/x509"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/util/cert"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/configobservation/csrsigning"
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/operatorclient"
+)
+
+const namespace = "kube_controller_manager_operator"
+
+// certificateSecrets are the cert key pairs managed by the operator. Only their first certificate is reported.
+var certificateSecrets = []struct{ namespace, name string }{
+	{operatorclient.OperatorNamespace, "csr-signer-signer"},
+	{operatorclient.OperatorNamespace, "csr-signer"},
+	{operatorclient.OperatorNamespace, "csr-signer-user"},
+	{operatorclient.TargetNamespace, "csr-signer"},
+	{operatorclient.TargetNamespace, "serving-cert"},
+}
+
+// caBundleConfigMaps are the CA bundles managed by the operator. Every entry is reported.
+var caBundleConfigMaps = []struct{ namespace, name string }{
+	{operatorclient.OperatorNamespace, "csr-controller-ca"},
+	{operatorclient.OperatorNamespace, "csr-signer-ca"},
+	{operatorclient.TargetNamespace, "serviceaccount-ca"},
+}
+
+var (
+	certificateLabels = []string{"type", "namespace", "name", "index", "common_name", "ca"}
+
+	notBeforeDesc = metrics.NewDesc(
+		namespace+"_certificate_not_before_seconds",
+		"The NotBefore of a certificate managed by the operator, as seconds since the epoch.",
+		certificateLabels, nil, metrics.ALPHA, "")
+	notAfterDesc = metrics.NewDesc(
+		namespace+"_certificate_not_after_seconds",
+		"The NotAfter of a certificate managed by the operator, as seconds since the epoch.",
+		certificateLabels, nil, metrics.ALPHA, "")
+	lastRotationDesc = metrics.NewDesc(
+		namespace+"_certificate_last_rotation_timestamp_seconds",
+		"When the certificate of a cert key pair managed by the operator was last rotated, as seconds since the epoch.",
+		[]string{"namespace", "name"}, nil, metrics.ALPHA, "")
+	promotionDelayDesc = metrics.NewDesc(
+		namespace+"_csr_signer_promotion_delay_seconds",
+		"How long until a new CSR signer is copied to the kube-controller-manager, 0 if none is pending.",
+		nil, nil, metrics.ALPHA, "")
+	expiryWarningWindowDesc = metrics.NewDesc(
+		namespace+"_certificate_expiry_warning_window_seconds",
+		"How long before their expiry the signers are alerted on.",
+		nil, nil, metrics.ALPHA, "")
+
+	csrSignerPromotionDelay atomic.Int64
+)
+
+// SetCSRSignerPromotionDelay records the delay computed by ManageCSRSigner before a new signer is used.
+func SetCSRSignerPromotionDelay(delay time.Duration) {
+	csrSignerPromotionDelay.Store(int64(delay))
+}
+
+type certificateCollector struct {
+	metrics.BaseStableCollector
+
+	secretLister          corev1listers.SecretLister
+	configMapLister       corev1listers.ConfigMapLister
+	defaultSignerValidity time.Duration
+}
+
+// Register registers the collector reporting the certificates managed by the operator in the legacy registry served by
+// the operator. The expiry warning window is read from the kube-controller-manager-csr-signing configmap.
+func Register(secretLister corev1listers.SecretLister, configMapLister corev1listers.ConfigMapLister, defaultSignerValidity time.Duration) {
+	legacyregistry.CustomMustRegister(&certificateCollector{
+		secretLister:          secretLister,
+		configMapLister:       configMapLister,
+		defaultSignerValidity: defaultSignerValidity,
+	})
+}
+
+func (c *certificateCollector) DescribeWithStability(ch chan<- *metrics.Desc) {
+	ch <- notBeforeDesc
+	ch <- notAfterDesc
+	ch <- lastRotationDesc
+	ch <- promotionDelayDesc
+	ch <- expiryWarningWindowDesc
+}
+
+func (c *certificateCollector) CollectWithStability(ch chan<- metrics.Metric) {
+	for _, location := range certificateSecrets {
+		secret, err := c.secretLister.Secrets(location.namespace).Get(location.name)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			klog.V(2).Infof("Failed to get secret %s/%s: %v", location.namespace, location.name, err)
+			continue
+		}
+		certificates, err := cert.ParseCertsPEM(secret.Data["tls.crt"])
+		if err != nil {
+			klog.V(2).Infof("Failed to parse the certificate of secret %s/%s: %v", location.namespace, location.name, err)
+			continue
+		}
+		collectCertificate(ch, "secret", location.namespace, location.name, 0, certificates[0])
+		ch <- metrics.NewLazyConstMetric(lastRotationDesc, metrics.GaugeValue, float64(lastRotation(secret.Annotations, certificates[0]).Unix()), location.namespace, location.name)
+	}
+
+	for _, location := range caBundleConfigMaps {
+		configMap, err := c.configMapLister.ConfigMaps(location.namespace).Get(location.name)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			klog.V(2).Infof("Failed to get configmap %s/%s: %v", location.namespace, location.name, err)
+			continue
+		}
+		if len(configMap.Data["ca-bundle.crt"]) == 0 {
+			continue
+		}
+		certificates, err := cert.ParseCertsPEM([]byte(configMap.Data["ca-bundle.crt"]))
+		if err != nil {
+			klog.V(2).Infof("Failed to parse the CA bundle of configmap %s/%s: %v", location.namespace, location.name, err)
+			continue
+		}
+		for i, certificate := range certificates {
+			collectCertificate(ch, "configmap", location.namespace, location.name, i, certificate)
+		}
+	}
+
+	ch <- metrics.NewLazyConstMetric(promotionDelayDesc, metrics.GaugeValue, time.Duration(csrSignerPromotionDelay.Load()).Seconds())
+	ch <- metrics.NewLazyConstMetric(expiryWarningWindowDesc, metrics.GaugeValue, c.expiryWarningWindow().Seconds())
+}
+
+func collectCertificate(ch chan<- metrics.Metric, resourceType, namespace, name string, index int, certificate *x509.Certificate) {
+	labels := []string{resourceType, namespace, name, strconv.Itoa(index), certificate.Subject.CommonName, strconv.FormatBool(certificate.IsCA)}
+	ch <- metrics.NewLazyConstMetric(notBeforeDesc, metrics.GaugeValue, float64(certificate.NotBefore.Unix()), labels...)
+	ch <- metrics.NewLazyConstMetric(notAfterDesc, metrics.GaugeValue, float64(certificate.NotAfter.Unix()), labels...)
+}
+
+// lastRotation prefers the not-before annotation set by the cert rotation, the certificates are backdated.
+func lastRotation(annotations map[string]string, certificate *x509.Certificate) time.Time {
+	if notBefore, err := time.Parse(time.RFC3339, annotations["auth.openshift.io/certificate-not-before"]); err == nil {
+		return notBefore
+	}
+	return certificate.NotBefore
+}
+
+// expiryWarningWindow returns the configured window. An invalid configmap is reported by the config observer, the
+// default window is used until it is fixed.
+func (c *certificateCollector) expiryWarningWindow() time.Duration {
+	settings, err := csrsigning.ReadSettings(c.configMapLister, c.defaultSignerValidity)
+	if err != nil {
+		klog.V(4).Infof("Using the default expiry warning window: %v", err)
+		// the default settings are always valid
+		settings, _ = csrsigning.DefaultSettings(c.defaultSignerValidity)
+	}
+	return settings.ExpiryWarningWindow
+}
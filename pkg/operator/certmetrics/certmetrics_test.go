@@ -0,0 +1,191 @@
+package certmetrics
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/component-base/metrics/testutil"
+
+	"github.com/openshift/library-go/pkg/crypto"
+)
+
+func makeCertificate(t *testing.T, commonName string, isCA bool, notBefore, notAfter int64) (certPEM, keyPEM []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: commonName},
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Unix(notBefore, 0),
+		NotAfter:              time.Unix(notAfter, 0),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM, keyPEM, err = (&crypto.TLSCertificateConfig{Certs: []*x509.Certificate{cert}, Key: key}).GetPEMBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return certPEM, keyPEM
+}
+
+func TestCollector(t *testing.T) {
+	signerCert, signerKey := makeCertificate(t, "kube-csr-signer_@1700000000", true, 1700000000, 1702592000)
+	servingCert, servingKey := makeCertificate(t, "kube-controller-manager.openshift-kube-controller-manager.svc", false, 1700000100, 1763072100)
+	oldCA, _ := makeCertificate(t, "old-signer", true, 1690000000, 1700000000)
+
+	tests := []struct {
+		name           string
+		objects        []runtime.Object
+		promotionDelay time.Duration
+		expected       string
+	}{
+		{
+			name: "no certificates",
+			expected: `
+# HELP kube_controller_manager_operator_certificate_expiry_warning_window_seconds [ALPHA] How long before their expiry the signers are alerted on.
+# TYPE kube_controller_manager_operator_certificate_expiry_warning_window_seconds gauge
+kube_controller_manager_operator_certificate_expiry_warning_window_seconds 604800
+# HELP kube_controller_manager_operator_csr_signer_promotion_delay_seconds [ALPHA] How long until a new CSR signer is copied to the kube-controller-manager, 0 if none is pending.
+# TYPE kube_controller_manager_operator_csr_signer_promotion_delay_seconds gauge
+kube_controller_manager_operator_csr_signer_promotion_delay_seconds 0
+`,
+		},
+		{
+			name: "secrets and bundles",
+			objects: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-controller-manager-operator", Name: "csr-signer", Annotations: map[string]string{
+						"auth.openshift.io/certificate-not-before": "2023-11-14T22:15:00Z",
+					}},
+					Data: map[string][]byte{"tls.crt": signerCert, "tls.key": signerKey},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-controller-manager", Name: "serving-cert"},
+					Data:       map[string][]byte{"tls.crt": servingCert, "tls.key": servingKey},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-controller-manager", Name: "csr-signer"},
+					Data:       map[string][]byte{"tls.crt": []byte("garbage")},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-controller-manager-operator", Name: "unrelated"},
+					Data:       map[string][]byte{"tls.crt": signerCert, "tls.key": signerKey},
+				},
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-controller-manager-operator", Name: "csr-controller-ca"},
+					Data:       map[string]string{"ca-bundle.crt": string(oldCA) + string(signerCert)},
+				},
+			},
+			promotionDelay: 4*time.Minute + 30*time.Second,
+			expected: `
+# HELP kube_controller_manager_operator_certificate_expiry_warning_window_seconds [ALPHA] How long before their expiry the signers are alerted on.
+# TYPE kube_controller_manager_operator_certificate_expiry_warning_window_seconds gauge
+kube_controller_manager_operator_certificate_expiry_warning_window_seconds 604800
+# HELP kube_controller_manager_operator_certificate_last_rotation_timestamp_seconds [ALPHA] When the certificate of a cert key pair managed by the operator was last rotated, as seconds since the epoch.
+# TYPE kube_controller_manager_operator_certificate_last_rotation_timestamp_seconds gauge
+kube_controller_manager_operator_certificate_last_rotation_timestamp_seconds{name="csr-signer",namespace="openshift-kube-controller-manager-operator"} 1.7000001e+09
+kube_controller_manager_operator_certificate_last_rotation_timestamp_seconds{name="serving-cert",namespace="openshift-kube-controller-manager"} 1.7000001e+09
+# HELP kube_controller_manager_operator_certificate_not_after_seconds [ALPHA] The NotAfter of a certificate managed by the operator, as seconds since the epoch.
+# TYPE kube_controller_manager_operator_certificate_not_after_seconds gauge
+kube_controller_manager_operator_certificate_not_after_seconds{ca="false",common_name="kube-controller-manager.openshift-kube-controller-manager.svc",index="0",name="serving-cert",namespace="openshift-kube-controller-manager",type="secret"} 1.7630721e+09
+kube_controller_manager_operator_certificate_not_after_seconds{ca="true",common_name="kube-csr-signer_@1700000000",index="0",name="csr-signer",namespace="openshift-kube-controller-manager-operator",type="secret"} 1.702592e+09
+kube_controller_manager_operator_certificate_not_after_seconds{ca="true",common_name="kube-csr-signer_@1700000000",index="1",name="csr-controller-ca",namespace="openshift-kube-controller-manager-operator",type="configmap"} 1.702592e+09
+kube_controller_manager_operator_certificate_not_after_seconds{ca="true",common_name="old-signer",index="0",name="csr-controller-ca",namespace="openshift-kube-controller-manager-operator",type="configmap"} 1.7e+09
+# HELP kube_controller_manager_operator_certificate_not_before_seconds [ALPHA] The NotBefore of a certificate managed by the operator, as seconds since the epoch.
+# TYPE kube_controller_manager_operator_certificate_not_before_seconds gauge
+kube_controller_manager_operator_certificate_not_before_seconds{ca="false",common_name="kube-controller-manager.openshift-kube-controller-manager.svc",index="0",name="serving-cert",namespace="openshift-kube-controller-manager",type="secret"} 1.7000001e+09
+kube_controller_manager_operator_certificate_not_before_seconds{ca="true",common_name="kube-csr-signer_@1700000000",index="0",name="csr-signer",namespace="openshift-kube-controller-manager-operator",type="secret"} 1.7e+09
+kube_controller_manager_operator_certificate_not_before_seconds{ca="true",common_name="kube-csr-signer_@1700000000",index="1",name="csr-controller-ca",namespace="openshift-kube-controller-manager-operator",type="configmap"} 1.7e+09
+kube_controller_manager_operator_certificate_not_before_seconds{ca="true",common_name="old-signer",index="0",name="csr-controller-ca",namespace="openshift-kube-controller-manager-operator",type="configmap"} 1.69e+09
+# HELP kube_controller_manager_operator_csr_signer_promotion_delay_seconds [ALPHA] How long until a new CSR signer is copied to the kube-controller-manager, 0 if none is pending.
+# TYPE kube_controller_manager_operator_csr_signer_promotion_delay_seconds gauge
+kube_controller_manager_operator_csr_signer_promotion_delay_seconds 270
+`,
+		},
+		{
+			name: "configured window",
+			objects: []runtime.Object{
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "kube-controller-manager-csr-signing"},
+					Data:       map[string]string{"csr-signing.yaml": "expiryWarningWindow: 72h"},
+				},
+			},
+			expected: `
+# HELP kube_controller_manager_operator_certificate_expiry_warning_window_seconds [ALPHA] How long before their expiry the signers are alerted on.
+# TYPE kube_controller_manager_operator_certificate_expiry_warning_window_seconds gauge
+kube_controller_manager_operator_certificate_expiry_warning_window_seconds 259200
+# HELP kube_controller_manager_operator_csr_signer_promotion_delay_seconds [ALPHA] How long until a new CSR signer is copied to the kube-controller-manager, 0 if none is pending.
+# TYPE kube_controller_manager_operator_csr_signer_promotion_delay_seconds gauge
+kube_controller_manager_operator_csr_signer_promotion_delay_seconds 0
+`,
+		},
+		{
+			name: "invalid window",
+			objects: []runtime.Object{
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "kube-controller-manager-csr-signing"},
+					Data:       map[string]string{"csr-signing.yaml": "expiryWarningWindow: 720h"},
+				},
+			},
+			expected: `
+# HELP kube_controller_manager_operator_certificate_expiry_warning_window_seconds [ALPHA] How long before their expiry the signers are alerted on.
+# TYPE kube_controller_manager_operator_certificate_expiry_warning_window_seconds gauge
+kube_controller_manager_operator_certificate_expiry_warning_window_seconds 604800
+# HELP kube_controller_manager_operator_csr_signer_promotion_delay_seconds [ALPHA] How long until a new CSR signer is copied to the kube-controller-manager, 0 if none is pending.
+# TYPE kube_controller_manager_operator_csr_signer_promotion_delay_seconds gauge
+kube_controller_manager_operator_csr_signer_promotion_delay_seconds 0
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			secretIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			configMapIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			for _, obj := range test.objects {
+				switch obj.(type) {
+				case *corev1.Secret:
+					if err := secretIndexer.Add(obj); err != nil {
+						t.Fatal(err)
+					}
+				case *corev1.ConfigMap:
+					if err := configMapIndexer.Add(obj); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			SetCSRSignerPromotionDelay(test.promotionDelay)
+			defer SetCSRSignerPromotionDelay(0)
+
+			collector := &certificateCollector{
+				secretLister:          corev1listers.NewSecretLister(secretIndexer),
+				configMapLister:       corev1listers.NewConfigMapLister(configMapIndexer),
+				defaultSignerValidity: 30 * 24 * time.Hour,
+			}
+			if err := testutil.CustomCollectAndCompare(collector, strings.NewReader(test.expected)); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -3,9 +3,13 @@ package certrotationcontroller
 import (
 	"context"
 	"fmt"
+	"os"
+	"sync"
 	"time"
 
+	"k8s.io/apimachinery/pkg/util/wait"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 
@@ -16,6 +20,7 @@ import (
 
 	features "github.com/openshift/api/features"
 	configinformers "github.com/openshift/client-go/config/informers/externalversions"
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/configobservation/csrsigning"
 	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/operatorclient"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
@@ -24,6 +29,10 @@ import (
 type CertRotationController struct {
 	certRotators []factory.Controller
 	cachesToSync []cache.InformerSynced
+
+	newCSRSigningCertRotator  func(settings csrsigning.Settings) factory.Controller
+	csrSigningConfigMapLister corev1listers.ConfigMapLister
+	defaultSignerValidity     time.Duration
 }
 
 func NewCertRotationController(
@@ -80,18 +89,13 @@ func newCertRotationController(
 ) (*CertRotationController, error) {
 	ret := &CertRotationController{}
 
-	refreshPeriod := time.Hour * 24 * 30
-
 	featureGates, err := featureGateAccessor.CurrentFeatureGates()
 	if err != nil {
 		return nil, fmt.Errorf("unable to get FeatureGates: %w", err)
 	}
 
-	// This featuregate should be enabled on install time, we don't support enabling or disabling it after install.
-	if featureGates.Enabled(features.FeatureShortCertRotation) {
-		refreshPeriod = time.Hour * 2
-		klog.Infof("Setting refreshPeriod to %v", refreshPeriod)
-	}
+	defaultSignerValidity := csrsigning.DefaultSignerValidity(featureGates)
+	klog.Infof("Setting the default CSR signer validity to %v", defaultSignerValidity)
 
 	var pkiProfileProvider pki.PKIProfileProvider
 	if featureGates.Enabled(features.FeatureGateConfigurablePKI) {
@@ -102,61 +106,68 @@ func newCertRotationController(
 		pkiProfileProvider = pki.NewClusterPKIProfileProvider(configInformers.Config().V1alpha1().PKIs().Lister())
 	}
 
-	certRotator := certrotation.NewCertRotationController(
-		"CSRSigningCert",
-		certrotation.RotatedSigningCASecret{
-			Namespace: operatorclient.OperatorNamespace,
-			// this is not a typo, this is the signer of the signer
-			Name: "csr-signer-signer",
-			AdditionalAnnotations: certrotation.AdditionalAnnotations{
-				JiraComponent: "kube-controller-manager",
+	csrSigningConfigMaps := kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().ConfigMaps()
+	ret.cachesToSync = append(ret.cachesToSync, csrSigningConfigMaps.Informer().HasSynced)
+	ret.csrSigningConfigMapLister = csrSigningConfigMaps.Lister()
+	ret.defaultSignerValidity = defaultSignerValidity
+
+	// the lifetimes are read from the kube-controller-manager-csr-signing configmap once the caches are synced
+	ret.newCSRSigningCertRotator = func(settings csrsigning.Settings) factory.Controller {
+		return certrotation.NewCertRotationController(
+			"CSRSigningCert",
+			certrotation.RotatedSigningCASecret{
+				Namespace: operatorclient.OperatorNamespace,
+				// this is not a typo, this is the signer of the signer
+				Name: "csr-signer-signer",
+				AdditionalAnnotations: certrotation.AdditionalAnnotations{
+					JiraComponent: "kube-controller-manager",
+				},
+				// the csr-signer-signer is valid twice as long as the csr-signer and is rotated once a csr-signer lifetime passed
+				Validity:               2 * settings.SignerValidity,
+				Refresh:                settings.SignerValidity,
+				RefreshOnlyWhenExpired: refreshOnlyWhenExpired,
+				CertificateName:        "kube-controller-manager.csr-signer-signer",
+				PKIProfileProvider:     pkiProfileProvider,
+				Informer:               kubeInformersForNamespaces.InformersFor(operatorclient.OperatorNamespace).Core().V1().Secrets(),
+				Lister:                 kubeInformersForNamespaces.InformersFor(operatorclient.OperatorNamespace).Core().V1().Secrets().Lister(),
+				Client:                 secretsGetter,
+				EventRecorder:          eventRecorder,
 			},
-			Validity:               refreshPeriod * 2,
-			Refresh:                refreshPeriod,
-			RefreshOnlyWhenExpired: refreshOnlyWhenExpired,
-			CertificateName:        "kube-controller-manager.csr-signer-signer",
-			PKIProfileProvider:     pkiProfileProvider,
-			Informer:               kubeInformersForNamespaces.InformersFor(operatorclient.OperatorNamespace).Core().V1().Secrets(),
-			Lister:                 kubeInformersForNamespaces.InformersFor(operatorclient.OperatorNamespace).Core().V1().Secrets().Lister(),
-			Client:                 secretsGetter,
-			EventRecorder:          eventRecorder,
-		},
-		certrotation.CABundleConfigMap{
-			Namespace: operatorclient.OperatorNamespace,
-			Name:      "csr-controller-signer-ca",
-			AdditionalAnnotations: certrotation.AdditionalAnnotations{
-				JiraComponent: "kube-controller-manager",
+			certrotation.CABundleConfigMap{
+				Namespace: operatorclient.OperatorNamespace,
+				Name:      "csr-controller-signer-ca",
+				AdditionalAnnotations: certrotation.AdditionalAnnotations{
+					JiraComponent: "kube-controller-manager",
+				},
+				RefreshOnlyWhenExpired: refreshOnlyWhenExpired,
+				Informer:               kubeInformersForNamespaces.InformersFor(operatorclient.OperatorNamespace).Core().V1().ConfigMaps(),
+				Lister:                 kubeInformersForNamespaces.InformersFor(operatorclient.OperatorNamespace).Core().V1().ConfigMaps().Lister(),
+				Client:                 configMapsGetter,
+				EventRecorder:          eventRecorder,
 			},
-			RefreshOnlyWhenExpired: refreshOnlyWhenExpired,
-			Informer:               kubeInformersForNamespaces.InformersFor(operatorclient.OperatorNamespace).Core().V1().ConfigMaps(),
-			Lister:                 kubeInformersForNamespaces.InformersFor(operatorclient.OperatorNamespace).Core().V1().ConfigMaps().Lister(),
-			Client:                 configMapsGetter,
-			EventRecorder:          eventRecorder,
-		},
-		certrotation.RotatedSelfSignedCertKeySecret{
-			Namespace: operatorclient.OperatorNamespace,
-			Name:      "csr-signer",
-			AdditionalAnnotations: certrotation.AdditionalAnnotations{
-				JiraComponent: "kube-controller-manager",
+			certrotation.RotatedSelfSignedCertKeySecret{
+				Namespace: operatorclient.OperatorNamespace,
+				Name:      "csr-signer",
+				AdditionalAnnotations: certrotation.AdditionalAnnotations{
+					JiraComponent: "kube-controller-manager",
+				},
+				Validity:               settings.SignerValidity,
+				Refresh:                settings.SignerRefresh,
+				RefreshOnlyWhenExpired: refreshOnlyWhenExpired,
+				CertificateName:        "kube-controller-manager.csr-signer",
+				PKIProfileProvider:     pkiProfileProvider,
+				CertCreator: &certrotation.SignerRotation{
+					SignerName: "kube-csr-signer",
+				},
+				Informer:      kubeInformersForNamespaces.InformersFor(operatorclient.OperatorNamespace).Core().V1().Secrets(),
+				Lister:        kubeInformersForNamespaces.InformersFor(operatorclient.OperatorNamespace).Core().V1().Secrets().Lister(),
+				Client:        secretsGetter,
+				EventRecorder: eventRecorder,
 			},
-			Validity:               refreshPeriod,
-			Refresh:                refreshPeriod / 2,
-			RefreshOnlyWhenExpired: refreshOnlyWhenExpired,
-			CertificateName:        "kube-controller-manager.csr-signer",
-			PKIProfileProvider:     pkiProfileProvider,
-			CertCreator: &certrotation.SignerRotation{
-				SignerName: "kube-csr-signer",
-			},
-			Informer:      kubeInformersForNamespaces.InformersFor(operatorclient.OperatorNamespace).Core().V1().Secrets(),
-			Lister:        kubeInformersForNamespaces.InformersFor(operatorclient.OperatorNamespace).Core().V1().Secrets().Lister(),
-			Client:        secretsGetter,
-			EventRecorder: eventRecorder,
-		},
-		eventRecorder,
-		&certrotation.StaticPodConditionStatusReporter{OperatorClient: operatorClient},
-	)
-
-	ret.certRotators = append(ret.certRotators, certRotator)
+			eventRecorder,
+			&certrotation.StaticPodConditionStatusReporter{OperatorClient: operatorClient},
+		)
+	}
 
 	return ret, nil
 }
@@ -165,8 +176,50 @@ func (c *CertRotationController) Run(ctx context.Context, workers int) {
 	if !cache.WaitForNamedCacheSyncWithContext(ctx, c.cachesToSync...) {
 		return
 	}
+
+	settings := c.csrSigningSettings()
+	klog.Infof("Rotating the CSR signer after %v of its validity of %v", settings.SignerRefresh, settings.SignerValidity)
+	certRotators := append(c.certRotators, c.newCSRSigningCertRotator(settings))
+	// like a change of the feature gates, a change of the lifetimes restarts the process to rotate with the new ones
+	go wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if current, changed := c.csrSigningSettingsChanged(settings); changed {
+			klog.Infof("The CSR signer lifetimes changed from %+v to %+v, exiting to rotate with them", settings, current)
+			os.Exit(0)
+		}
+	}, time.Minute)
+
 	syncCtx := context.WithValue(ctx, certrotation.RunOnceContextKey, false)
-	for _, certRotator := range c.certRotators {
-		go certRotator.Run(syncCtx, workers)
+	var wg sync.WaitGroup
+	for _, certRotator := range certRotators {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			certRotator.Run(syncCtx, workers)
+		}()
+	}
+	wg.Wait()
+}
+
+// csrSigningSettings reads the lifetimes of the CSR signer. An invalid configmap is reported by the config observer,
+// the default lifetimes are used until it is fixed.
+func (c *CertRotationController) csrSigningSettings() csrsigning.Settings {
+	settings, err := csrsigning.ReadSettings(c.csrSigningConfigMapLister, c.defaultSignerValidity)
+	if err == nil {
+		return settings
+	}
+	klog.Warningf("Rotating the CSR signers with the default lifetimes: %v", err)
+	// the default lifetimes are always valid
+	settings, _ = csrsigning.DefaultSettings(c.defaultSignerValidity)
+	return settings
+}
+
+// csrSigningSettingsChanged returns the current lifetimes of the CSR signer and whether they differ from the running
+// ones. An invalid configmap does not change the running lifetimes, nor does the issued certificate duration the
+// signers are not rotated with.
+func (c *CertRotationController) csrSigningSettingsChanged(running csrsigning.Settings) (csrsigning.Settings, bool) {
+	settings, err := csrsigning.ReadSettings(c.csrSigningConfigMapLister, c.defaultSignerValidity)
+	if err != nil {
+		return running, false
 	}
+	return settings, settings.SignerValidity != running.SignerValidity || settings.SignerRefresh != running.SignerRefresh
 }
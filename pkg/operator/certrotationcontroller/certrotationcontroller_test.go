@@ -0,0 +1,50 @@
+package certrotationcontroller
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/configobservation/csrsigning"
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/operatorclient"
+)
+
+func TestCSRSigningSettingsChanged(t *testing.T) {
+	running := csrsigning.Settings{SignerValidity: 720 * time.Hour, SignerRefresh: 360 * time.Hour}
+	tests := []struct {
+		name     string
+		config   *string
+		expected bool
+	}{
+		{name: "no configmap"},
+		{name: "same lifetimes", config: stringPtr("signerValidity: 720h\nrefreshRatio: 0.5")},
+		{name: "issued certificate duration only", config: stringPtr("issuedCertificateDuration: 240h")},
+		{name: "invalid configmap", config: stringPtr("signerValidity: 10m")},
+		{name: "signer validity", config: stringPtr("signerValidity: 1440h"), expected: true},
+		{name: "refresh ratio", config: stringPtr("refreshRatio: 0.25"), expected: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if test.config != nil {
+				if err := indexer.Add(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: csrsigning.ConfigMapName, Namespace: operatorclient.GlobalUserSpecifiedConfigNamespace},
+					Data:       map[string]string{csrsigning.ConfigMapKey: *test.config},
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			c := &CertRotationController{
+				csrSigningConfigMapLister: corev1listers.NewConfigMapLister(indexer),
+				defaultSignerValidity:     720 * time.Hour,
+			}
+			if _, changed := c.csrSigningSettingsChanged(running); changed != test.expected {
+				t.Errorf("expected changed %v, got %v", test.expected, changed)
+			}
+		})
+	}
+}
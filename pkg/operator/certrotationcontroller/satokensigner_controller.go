@@ -3,59 +3,80 @@ package certrotationcontroller
 import (
 	"context"
 	"fmt"
+	"net"
 	"strings"
 	"time"
 
 	"k8s.io/klog/v2"
 
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	discoveryv1client "k8s.io/client-go/kubernetes/typed/discovery/v1"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
+	operatorv1client "github.com/openshift/client-go/operator/clientset/versioned/typed/operator/v1"
 	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/operatorclient"
 	"github.com/openshift/library-go/pkg/controller/factory"
-	"github.com/openshift/library-go/pkg/operator/encryption/crypto"
 	"github.com/openshift/library-go/pkg/operator/events"
 	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
 )
 
 const (
-	saTokenReadyTimeAnnotation = "kube-controller-manager.openshift.io/ready-to-use"
+	// saTokenTrustTimeout is how long a next signing key may wait for the kube-apiservers to trust it before the
+	// controller reports degraded. The key is still only promoted once it is trusted.
+	saTokenTrustTimeout = time.Hour
+
+	// bootstrapNodeGoneCondition records that the kube-apiserver of the bootstrap node was confirmed gone.
+	bootstrapNodeGoneCondition = "SATokenSignerBootstrapNodeGone"
 )
 
 type SATokenSignerController struct {
 	operatorClient  v1helpers.StaticPodOperatorClient
 	secretClient    corev1client.SecretsGetter
 	configMapClient corev1client.ConfigMapsGetter
-	endpointClient  corev1client.EndpointsGetter
 	podClient       corev1client.PodsGetter
 
-	confirmedBootstrapNodeGone bool
+	endpointSliceClient discoveryv1client.EndpointSlicesGetter
+
+	// kube-apiserver revisions are checked for the next signing key before it is promoted
+	kubeAPIServerClient          operatorv1client.KubeAPIServersGetter
+	kubeAPIServerConfigMapClient corev1client.ConfigMapsGetter
+
+	// token secrets of all namespaces are listed once per revocation, they are not cached
+	tokenSecretClient corev1client.SecretsGetter
 }
 
 func NewSATokenSignerController(
 	operatorClient v1helpers.StaticPodOperatorClient,
 	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
 	kubeClient kubernetes.Interface,
+	kubeAPIServerClient operatorv1client.KubeAPIServersGetter,
 	eventRecorder events.Recorder,
 ) factory.Controller {
 	c := &SATokenSignerController{
-		operatorClient:  operatorClient,
-		secretClient:    v1helpers.CachedSecretGetter(kubeClient.CoreV1(), kubeInformersForNamespaces),
-		configMapClient: v1helpers.CachedConfigMapGetter(kubeClient.CoreV1(), kubeInformersForNamespaces),
-		endpointClient:  kubeClient.CoreV1(),
-		podClient:       kubeClient.CoreV1(),
+		operatorClient:               operatorClient,
+		secretClient:                 v1helpers.CachedSecretGetter(kubeClient.CoreV1(), kubeInformersForNamespaces),
+		configMapClient:              v1helpers.CachedConfigMapGetter(kubeClient.CoreV1(), kubeInformersForNamespaces),
+		podClient:                    kubeClient.CoreV1(),
+		endpointSliceClient:          kubeClient.DiscoveryV1(),
+		kubeAPIServerClient:          kubeAPIServerClient,
+		kubeAPIServerConfigMapClient: kubeClient.CoreV1(),
+		tokenSecretClient:            kubeClient.CoreV1(),
 	}
 
 	return factory.New().WithInformers(
 		kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().Secrets().Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().ConfigMaps().Informer(),
 		kubeInformersForNamespaces.InformersFor(operatorclient.GlobalMachineSpecifiedConfigNamespace).Core().V1().ConfigMaps().Informer(),
 		kubeInformersForNamespaces.InformersFor(operatorclient.OperatorNamespace).Core().V1().Secrets().Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.OperatorNamespace).Core().V1().ConfigMaps().Informer(),
 		kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().Secrets().Informer(),
 		operatorClient.Informer(),
 	).ResyncEvery(time.Minute).WithSync(c.sync).ToController("SATokenSignerController", eventRecorder)
@@ -72,7 +93,11 @@ func (c *SATokenSignerController) sync(ctx context.Context, syncCtx factory.Sync
 		condition.Reason = "Error"
 		condition.Message = syncErr.Error()
 	}
-	if _, _, updateErr := v1helpers.UpdateStatus(ctx, c.operatorClient, v1helpers.UpdateConditionFn(condition)); updateErr != nil {
+	if _, _, updateErr := v1helpers.UpdateStatus(ctx, c.operatorClient,
+		v1helpers.UpdateConditionFn(condition),
+		v1helpers.UpdateConditionFn(c.rotationCondition(ctx)),
+		v1helpers.UpdateConditionFn(c.revocationCondition(ctx)),
+	); updateErr != nil {
 		return updateErr
 	}
 
@@ -93,51 +118,83 @@ func isUnexpectedAddressesError(err error) bool {
 }
 
 // we cannot rotate before the bootstrap server goes away because doing so would mean the bootstrap server would reject
-// tokens that should be valid.  To test this, we go through kubernetes.default.svc endpoints and see if any of them
-// are not in the list of known pod hosts.  We only have to do this once because the bootstrap node never comes back
+// tokens that should be valid.  To test this, we go through the kubernetes.default.svc EndpointSlices of all address
+// families and see if any of their ready endpoints are not in the list of known pod hosts.  We only have to do this once
+// because the bootstrap node never comes back, the result is kept in the operator status to survive restarts.
 func (c *SATokenSignerController) isPastBootstrapNode(ctx context.Context, syncCtx factory.SyncContext) error {
-	if c.confirmedBootstrapNodeGone {
+	_, operatorStatus, _, err := c.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return err
+	}
+	if v1helpers.IsOperatorConditionTrue(operatorStatus.Conditions, bootstrapNodeGoneCondition) {
 		return nil
 	}
 
-	nodeIPs := sets.String{}
+	nodeIPs := sets.New[string]()
 	apiServerPods, err := c.podClient.Pods("openshift-kube-apiserver").List(ctx, metav1.ListOptions{LabelSelector: "app=openshift-kube-apiserver"})
 	if err != nil {
 		return err
 	}
 	for _, pod := range apiServerPods.Items {
-		nodeIPs.Insert(pod.Status.HostIP)
+		// HostIPs lists the addresses of all families on dual-stack nodes, HostIP only the primary one
+		nodeIPs.Insert(normalizeIP(pod.Status.HostIP))
+		for _, hostIP := range pod.Status.HostIPs {
+			nodeIPs.Insert(normalizeIP(hostIP.IP))
+		}
 	}
 
-	kubeEndpoints, err := c.endpointClient.Endpoints("default").Get(ctx, "kubernetes", metav1.GetOptions{})
+	kubeEndpointSlices, err := c.endpointSliceClient.EndpointSlices("default").List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{discoveryv1.LabelServiceName: "kubernetes"}).String(),
+	})
 	if err != nil {
 		return err
 	}
-	if len(kubeEndpoints.Subsets) == 0 {
-		err := fmt.Errorf("missing kubernetes endpoints subsets")
-		syncCtx.Recorder().Warning("SATokenSignerControllerStuck", err.Error())
-		return err
-	}
-	unexpectedEndpoints := sets.String{}
-	for _, subset := range kubeEndpoints.Subsets {
-		for _, address := range subset.Addresses {
-			if !nodeIPs.Has(address.IP) {
-				unexpectedEndpoints.Insert(address.IP)
+	endpoints := sets.New[string]()
+	for _, slice := range kubeEndpointSlices.Items {
+		if slice.AddressType != discoveryv1.AddressTypeIPv4 && slice.AddressType != discoveryv1.AddressTypeIPv6 {
+			continue
+		}
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.Conditions.Ready != nil && !*endpoint.Conditions.Ready {
+				continue
+			}
+			for _, address := range endpoint.Addresses {
+				endpoints.Insert(normalizeIP(address))
 			}
 		}
 	}
-	if len(unexpectedEndpoints) != 0 {
-		err := &unexpectedAddressesError{message: fmt.Sprintf("unexpected addresses: %v", strings.Join(unexpectedEndpoints.List(), ","))}
+	if endpoints.Len() == 0 {
+		err := fmt.Errorf("missing ready kubernetes endpoints in the EndpointSlices of default/kubernetes")
+		syncCtx.Recorder().Warning("SATokenSignerControllerStuck", err.Error())
+		return err
+	}
+	if unexpectedEndpoints := endpoints.Difference(nodeIPs); unexpectedEndpoints.Len() != 0 {
+		err := &unexpectedAddressesError{message: fmt.Sprintf("unexpected addresses: %v", strings.Join(sets.List(unexpectedEndpoints), ","))}
 		syncCtx.Recorder().Event("SATokenSignerControllerStuck", err.Error())
 		return err
 	}
 
 	// we have confirmed that the bootstrap node is gone
+	if _, _, err := v1helpers.UpdateStatus(ctx, c.operatorClient, v1helpers.UpdateConditionFn(operatorv1.OperatorCondition{
+		Type:    bootstrapNodeGoneCondition,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "ExpectedEndpoints",
+		Message: fmt.Sprintf("The kubernetes endpoints %s are all kube-apiserver pods", strings.Join(sets.List(endpoints), ",")),
+	})); err != nil {
+		return err
+	}
 	syncCtx.Recorder().Event("SATokenSignerControllerOK", "found expected kube-apiserver endpoints")
-	c.confirmedBootstrapNodeGone = true
 	return nil
 }
 
+// normalizeIP returns the canonical form of the address, IPv6 addresses can be written in several ways.
+func normalizeIP(address string) string {
+	if ip := net.ParseIP(address); ip != nil {
+		return ip.String()
+	}
+	return address
+}
+
 func (c *SATokenSignerController) syncWorker(ctx context.Context, syncCtx factory.SyncContext) error {
 	if pastBootstrapErr := c.isPastBootstrapNode(ctx, syncCtx); pastBootstrapErr != nil {
 		// if we are not past bootstrapping, then if we're missing the service-account-private-key we need to prime it from the
@@ -157,22 +214,69 @@ func (c *SATokenSignerController) syncWorker(ctx context.Context, syncCtx factor
 		return err
 	}
 
+	// an invalid config is reported once the current signing key is taken care of
+	config, configErr := readSATokenSigningConfig(ctx, c.configMapClient)
+	revocationRequestID, err := c.saTokenRevocationRequested()
+	if err != nil {
+		return err
+	}
+
+	current, err := c.secretClient.Secrets(operatorclient.TargetNamespace).Get(ctx, "service-account-private-key", metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if errors.IsNotFound(err) {
+		current = nil
+	}
+	inUse := sets.New[string]()
+	if current != nil {
+		currentFingerprint, err := saTokenPrivateKeyPEMFingerprint(current.Data["service-account.key"])
+		if err != nil {
+			return fmt.Errorf("service-account-private-key in %s: %w", operatorclient.TargetNamespace, err)
+		}
+		inUse.Insert(currentFingerprint)
+	}
+
 	needNewSATokenSigningKey := false
 	saTokenSigner, err := c.secretClient.Secrets(operatorclient.OperatorNamespace).Get(ctx, "next-service-account-private-key", metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	// every key generated while a request is set records it, a request is handled once
+	revokeNow := len(revocationRequestID) > 0 &&
+		(current == nil || current.Annotations[saTokenRevocationRequestAnnotation] != revocationRequestID) &&
+		(saTokenSigner == nil || saTokenSigner.Annotations[saTokenRevocationRequestAnnotation] != revocationRequestID)
 	if errors.IsNotFound(err) {
+		saTokenSigner = nil
 		needNewSATokenSigningKey = true
-	} else if err != nil {
-		return err
 	} else {
-		err := crypto.CheckRSAKeyPair(saTokenSigner.Data["service-account.pub"], saTokenSigner.Data["service-account.key"])
+		algorithm, err := checkSATokenSigningKeyPair(saTokenSigner.Data["service-account.pub"], saTokenSigner.Data["service-account.key"])
 		if err != nil {
 			klog.Errorf("key pair is invalid: %v", err)
 			needNewSATokenSigningKey = true
+		} else if revokeNow {
+			// a pending next key is revoked as well, it is stored next to the key in use
+			if nextFingerprint, err := saTokenPublicKeyPEMFingerprint(saTokenSigner.Data["service-account.pub"]); err == nil {
+				inUse.Insert(nextFingerprint)
+			}
+			needNewSATokenSigningKey = true
+		} else if config != nil && algorithm != config.KeyAlgorithm {
+			// the public key of the current signing key stays published, its tokens keep being accepted
+			syncCtx.Recorder().Eventf("SATokenSigningKeyAlgorithmChanged", "Replacing the %s next-service-account-private-key with a %s key", algorithm, config.KeyAlgorithm)
+			needNewSATokenSigningKey = true
+		} else if saTokenSigningKeyRotationDue(config, saTokenSigner, current, time.Now()) {
+			syncCtx.Recorder().Eventf("SATokenSigningKeyRotationDue", "Replacing the service account signing key generated at %s after the rotationInterval of %s",
+				saTokenSigningKeyGeneratedAt(current).UTC().Format(time.RFC3339), config.RotationInterval.Duration)
+			needNewSATokenSigningKey = true
 		}
 	}
 
 	if needNewSATokenSigningKey {
-		pubKeyPEM, privKeyPEM, err := crypto.GenerateRSAKeyPair()
+		algorithm := SATokenSigningKeyRSA
+		if config != nil {
+			algorithm = config.KeyAlgorithm
+		}
+		pubKeyPEM, privKeyPEM, err := generateSATokenSigningKeyPair(algorithm)
 		if err != nil {
 			return err
 		}
@@ -180,21 +284,30 @@ func (c *SATokenSignerController) syncWorker(ctx context.Context, syncCtx factor
 		saTokenSigner = &corev1.Secret{
 			ObjectMeta: metav1.ObjectMeta{
 				Namespace: operatorclient.OperatorNamespace, Name: "next-service-account-private-key",
-				Annotations: map[string]string{saTokenReadyTimeAnnotation: time.Now().Add(5 * time.Minute).Format(time.RFC3339)},
+				Annotations: map[string]string{saTokenGeneratedAtAnnotation: time.Now().Format(time.RFC3339)},
 			},
 			Data: map[string][]byte{
 				"service-account.key": privKeyPEM,
 				"service-account.pub": pubKeyPEM,
 			},
 		}
+		if len(revocationRequestID) > 0 {
+			saTokenSigner.Annotations[saTokenRevocationRequestAnnotation] = revocationRequestID
+		}
+		if revokeNow {
+			// the keys the request revokes are stored with the new key, a restarted operator resumes from there
+			saTokenSigner.Annotations[saTokenRevokedKeysAnnotation] = strings.Join(sets.List(inUse), ",")
+			syncCtx.Recorder().Eventf("SATokenSigningKeyRevocationRequested", "Revoking the service account signing keys %s for request %q",
+				strings.Join(sets.List(inUse), ", "), revocationRequestID)
+		}
 
 		saTokenSigner, _, err = resourceapply.ApplySecret(ctx, c.secretClient, syncCtx.Recorder(), saTokenSigner)
 		if err != nil {
 			return err
 		}
-		// requeue for after we should have recovered
-		syncCtx.Queue().AddAfter(syncCtx.QueueKey(), 5*time.Minute+10*time.Second)
 	}
+	revoked := saTokenRevokedFingerprints(saTokenSigner)
+	revoking := revoked.Len() > 0 && saTokenSigner.Annotations[saTokenRevocationRequestAnnotation] == revocationRequestID
 
 	saTokenSigningCerts, err := c.configMapClient.ConfigMaps(operatorclient.GlobalMachineSpecifiedConfigNamespace).Get(ctx, "sa-token-signing-certs", metav1.GetOptions{})
 	if err != nil && !errors.IsNotFound(err) {
@@ -207,43 +320,52 @@ func (c *SATokenSignerController) syncWorker(ctx context.Context, syncCtx factor
 		}
 	}
 	currPublicKey := string(saTokenSigner.Data["service-account.pub"])
-	hasThisPublicKey := false
-	for _, value := range saTokenSigningCerts.Data {
-		if value == currPublicKey {
-			hasThisPublicKey = true
-		}
+	saTokenSigningCerts, err = updateSATokenSigningCerts(syncCtx.Recorder(), saTokenSigningCerts, currPublicKey, inUse.Difference(revoked), revoked, config.publicKeyRetention(), time.Now())
+	if err != nil {
+		return err
+	}
+	if _, _, err := resourceapply.ApplyConfigMap(ctx, c.configMapClient, syncCtx.Recorder(), saTokenSigningCerts); err != nil {
+		return err
 	}
-	if !hasThisPublicKey {
-		saTokenSigningCerts.Data[fmt.Sprintf("service-account-%03d.pub", len(saTokenSigningCerts.Data)+1)] = currPublicKey
-		saTokenSigningCerts, _, err = resourceapply.ApplyConfigMap(ctx, c.configMapClient, syncCtx.Recorder(), saTokenSigningCerts)
+	jwks, err := saTokenSigningJWKS(saTokenSigningCerts)
+	if err != nil {
+		return err
+	}
+	if _, _, err := resourceapply.ApplyConfigMap(ctx, c.configMapClient, syncCtx.Recorder(), jwks); err != nil {
+		return err
+	}
+
+	// the next key is only promoted once every kube-apiserver trusts it, the ones that do not would reject the tokens
+	// signed with it. A key replacing revoked keys is promoted right away, the revoked keys must not sign any longer.
+	if !revoking && (current == nil || string(current.Data["service-account.pub"]) != currPublicKey) {
+		trusted, reason, err := c.kubeAPIServersTrust(ctx, currPublicKey)
 		if err != nil {
 			return err
 		}
+		if !trusted {
+			if generatedAt := saTokenSigningKeyGeneratedAt(saTokenSigner); time.Since(generatedAt) > saTokenTrustTimeout {
+				return fmt.Errorf("next-service-account-private-key generated at %s is not trusted by all kube-apiservers after %s: %s",
+					generatedAt.UTC().Format(time.RFC3339), saTokenTrustTimeout, reason)
+			}
+			klog.V(2).Infof("Waiting to promote next-service-account-private-key: %s", reason)
+			return configErr
+		}
 	}
 
-	// now check to see if the next-sa-private-key has been around long enough to be promoted.  We're waiting for the kube-apiserver
-	// to pick up the change
-	// TODO have a better signal for determining the level of cert trust.  This is a general problem for observing our cycles.
-	readyToPromote := false
-	saTokenReadyTime := saTokenSigner.Annotations[saTokenReadyTimeAnnotation]
-	if len(saTokenReadyTime) == 0 {
-		readyToPromote = true
-	}
-	promotionTime, err := time.Parse(time.RFC3339, saTokenReadyTime)
+	// the service-account-private-key is a revisioned secret, promoting a key rolls out a new kube-controller-manager
+	// revision
+	_, _, err = resourceapply.SyncSecret(ctx, c.secretClient, syncCtx.Recorder(),
+		operatorclient.OperatorNamespace, "next-service-account-private-key",
+		operatorclient.TargetNamespace, "service-account-private-key", []metav1.OwnerReference{})
 	if err != nil {
-		readyToPromote = true
-	}
-	if time.Now().After(promotionTime) {
-		readyToPromote = true
+		return err
 	}
 
-	// if we're past our promotion time, go ahead and synchronize over
-	if readyToPromote {
-		_, _, err := resourceapply.SyncSecret(ctx, c.secretClient, syncCtx.Recorder(),
-			operatorclient.OperatorNamespace, "next-service-account-private-key",
-			operatorclient.TargetNamespace, "service-account-private-key", []metav1.OwnerReference{})
-		return err
+	if revoking {
+		if err := c.reportRevokedTokenSecrets(ctx, syncCtx.Recorder(), revocationRequestID, revoked); err != nil {
+			return err
+		}
 	}
 
-	return nil
+	return configErr
 }
@@ -0,0 +1,119 @@
+package certrotationcontroller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/clock"
+	"k8s.io/utils/ptr"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func TestIsPastBootstrapNode(t *testing.T) {
+	apiServerPod := func(name string, hostIPs ...string) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: name, Labels: map[string]string{"app": "openshift-kube-apiserver"}},
+			Status:     corev1.PodStatus{HostIP: hostIPs[0]},
+		}
+		for _, hostIP := range hostIPs {
+			pod.Status.HostIPs = append(pod.Status.HostIPs, corev1.HostIP{IP: hostIP})
+		}
+		return pod
+	}
+	endpointSlice := func(name string, addressType discoveryv1.AddressType, endpoints ...discoveryv1.Endpoint) *discoveryv1.EndpointSlice {
+		return &discoveryv1.EndpointSlice{
+			ObjectMeta:  metav1.ObjectMeta{Namespace: "default", Name: name, Labels: map[string]string{discoveryv1.LabelServiceName: "kubernetes"}},
+			AddressType: addressType,
+			Endpoints:   endpoints,
+		}
+	}
+	endpoint := func(address string, ready bool) discoveryv1.Endpoint {
+		return discoveryv1.Endpoint{Addresses: []string{address}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(ready)}}
+	}
+	masters := []runtime.Object{
+		apiServerPod("kube-apiserver-master-0", "10.0.0.10", "fd00::10"),
+		apiServerPod("kube-apiserver-master-1", "10.0.0.11", "fd00::11"),
+	}
+
+	tests := []struct {
+		name               string
+		confirmed          bool
+		objects            []runtime.Object
+		expectedErr        string
+		expectedUnexpected bool
+	}{
+		{
+			name: "dual-stack endpoints of the masters",
+			objects: append([]runtime.Object{
+				endpointSlice("kubernetes", discoveryv1.AddressTypeIPv4, endpoint("10.0.0.10", true), endpoint("10.0.0.11", true)),
+				endpointSlice("kubernetes-ipv6", discoveryv1.AddressTypeIPv6, endpoint("fd00:0:0:0::10", true), endpoint("fd00::11", true)),
+			}, masters...),
+		},
+		{
+			name: "bootstrap node endpoint",
+			objects: append([]runtime.Object{
+				endpointSlice("kubernetes", discoveryv1.AddressTypeIPv4, endpoint("10.0.0.10", true), endpoint("10.0.0.11", true)),
+				endpointSlice("kubernetes-ipv6", discoveryv1.AddressTypeIPv6, endpoint("fd00::10", true), endpoint("fd00::99", true)),
+			}, masters...),
+			expectedErr:        "unexpected addresses: fd00::99",
+			expectedUnexpected: true,
+		},
+		{
+			name: "bootstrap node endpoint that is not ready",
+			objects: append([]runtime.Object{
+				endpointSlice("kubernetes", discoveryv1.AddressTypeIPv4, endpoint("10.0.0.10", true), endpoint("10.0.0.99", false)),
+			}, masters...),
+		},
+		{
+			name:        "no endpoints",
+			objects:     masters,
+			expectedErr: "missing ready kubernetes endpoints",
+		},
+		{
+			name:      "confirmed before a restart",
+			confirmed: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status := &operatorv1.StaticPodOperatorStatus{}
+			if test.confirmed {
+				status.Conditions = []operatorv1.OperatorCondition{{Type: bootstrapNodeGoneCondition, Status: operatorv1.ConditionTrue}}
+			}
+			operatorClient := v1helpers.NewFakeStaticPodOperatorClient(&operatorv1.StaticPodOperatorSpec{}, status, nil, nil)
+			kubeClient := fake.NewSimpleClientset(test.objects...)
+			c := &SATokenSignerController{
+				operatorClient:      operatorClient,
+				podClient:           kubeClient.CoreV1(),
+				endpointSliceClient: kubeClient.DiscoveryV1(),
+			}
+			syncCtx := factory.NewSyncContext("SATokenSignerController", events.NewInMemoryRecorder("satokensigner", clock.RealClock{}))
+
+			err := c.isPastBootstrapNode(context.TODO(), syncCtx)
+			switch {
+			case len(test.expectedErr) == 0 && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case len(test.expectedErr) > 0 && (err == nil || !strings.Contains(err.Error(), test.expectedErr)):
+				t.Fatalf("expected error %q, got %v", test.expectedErr, err)
+			}
+			if isUnexpectedAddressesError(err) != test.expectedUnexpected {
+				t.Errorf("expected unexpected addresses error %v, got %v", test.expectedUnexpected, err)
+			}
+
+			_, status, _, _ = operatorClient.GetStaticPodOperatorState()
+			if confirmed := v1helpers.IsOperatorConditionTrue(status.Conditions, bootstrapNodeGoneCondition); confirmed != (err == nil) {
+				t.Errorf("expected the bootstrap node to be confirmed gone %v, got %v", err == nil, confirmed)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package certrotationcontroller
+
+import (
+	gocrypto "crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/keyutil"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+// saTokenRetiredKeysAnnotation records on the sa-token-signing-certs configmap since when each published public key,
+// by fingerprint, is neither the signing key in use nor the next one.
+const saTokenRetiredKeysAnnotation = "kube-controller-manager.openshift.io/retired-public-keys"
+
+// saTokenPublicKeyFingerprint is the hex encoded SHA-256 of the DER encoded public key.
+func saTokenPublicKeyFingerprint(publicKey interface{}) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// saTokenPublicKeyPEMFingerprint returns the fingerprint of the first public key of the PEM block.
+func saTokenPublicKeyPEMFingerprint(publicKeyPEM []byte) (string, error) {
+	publicKeys, err := keyutil.ParsePublicKeysPEM(publicKeyPEM)
+	if err != nil {
+		return "", err
+	}
+	return saTokenPublicKeyFingerprint(publicKeys[0])
+}
+
+// saTokenPrivateKeyPEMFingerprint returns the fingerprint of the public key of the private key. The
+// service-account-private-key primed by the installer carries no public key.
+func saTokenPrivateKeyPEMFingerprint(privateKeyPEM []byte) (string, error) {
+	privateKey, err := keyutil.ParsePrivateKeyPEM(privateKeyPEM)
+	if err != nil {
+		return "", err
+	}
+	signer, ok := privateKey.(gocrypto.Signer)
+	if !ok {
+		return "", fmt.Errorf("unsupported private key type %T", privateKey)
+	}
+	return saTokenPublicKeyFingerprint(signer.Public())
+}
+
+// saTokenSigningCertsKey is the key of a public key in the sa-token-signing-certs configmap.
+func saTokenSigningCertsKey(fingerprint string) string {
+	return fmt.Sprintf("service-account-%s.pub", fingerprint)
+}
+
+// updateSATokenSigningCerts keys the public keys of the sa-token-signing-certs by fingerprint, publishes the next
+// public key and prunes public keys retired for longer than the retention. The signing keys in use are never pruned,
+// revoked keys are removed right away. Entries that cannot be parsed are kept as they are.
+func updateSATokenSigningCerts(recorder events.Recorder, existing *corev1.ConfigMap, nextPublicKey string, inUse, revoked sets.Set[string], retention time.Duration, now time.Time) (*corev1.ConfigMap, error) {
+	certs := existing.DeepCopy()
+	if certs.Annotations == nil {
+		certs.Annotations = map[string]string{}
+	}
+	retired := map[string]string{}
+	if raw := certs.Annotations[saTokenRetiredKeysAnnotation]; len(raw) > 0 {
+		if err := json.Unmarshal([]byte(raw), &retired); err != nil {
+			// the retirements are recorded again, which only delays pruning
+			klog.Warningf("Ignoring invalid %s annotation of configmap/%s: %v", saTokenRetiredKeysAnnotation, certs.Name, err)
+			retired = map[string]string{}
+		}
+	}
+
+	data := map[string]string{}
+	published := sets.New[string]()
+	for _, key := range sets.List(sets.KeySet(existing.Data)) {
+		value := existing.Data[key]
+		fingerprint, err := saTokenPublicKeyPEMFingerprint([]byte(value))
+		if err != nil {
+			klog.Warningf("Keeping unparseable %s of configmap/%s: %v", key, certs.Name, err)
+			data[key] = value
+			continue
+		}
+		data[saTokenSigningCertsKey(fingerprint)] = value
+		published.Insert(fingerprint)
+	}
+	nextFingerprint, err := saTokenPublicKeyPEMFingerprint([]byte(nextPublicKey))
+	if err != nil {
+		return nil, err
+	}
+	data[saTokenSigningCertsKey(nextFingerprint)] = nextPublicKey
+	published.Insert(nextFingerprint)
+
+	for _, fingerprint := range sets.List(published) {
+		if revoked.Has(fingerprint) && fingerprint != nextFingerprint {
+			delete(data, saTokenSigningCertsKey(fingerprint))
+			delete(retired, fingerprint)
+			recorder.Eventf("SATokenSigningPublicKeyRevoked", "Removed the revoked public key %s from configmap/%s, tokens signed with it are no longer accepted",
+				fingerprint, certs.Name)
+			continue
+		}
+		if inUse.Has(fingerprint) || fingerprint == nextFingerprint {
+			delete(retired, fingerprint)
+			continue
+		}
+		retiredAt, err := time.Parse(time.RFC3339, retired[fingerprint])
+		if err != nil {
+			retired[fingerprint] = now.UTC().Format(time.RFC3339)
+			continue
+		}
+		if now.Sub(retiredAt) > retention {
+			delete(data, saTokenSigningCertsKey(fingerprint))
+			delete(retired, fingerprint)
+			recorder.Eventf("SATokenSigningPublicKeyPruned", "Removed the public key %s retired at %s from configmap/%s, tokens signed with it are no longer accepted",
+				fingerprint, retiredAt.Format(time.RFC3339), certs.Name)
+		}
+	}
+	for fingerprint := range retired {
+		if !published.Has(fingerprint) {
+			delete(retired, fingerprint)
+		}
+	}
+
+	// the annotation is kept when empty, apply merges annotations and would not remove it
+	raw, err := json.Marshal(retired)
+	if err != nil {
+		return nil, err
+	}
+	certs.Data = data
+	certs.Annotations[saTokenRetiredKeysAnnotation] = string(raw)
+	return certs, nil
+}
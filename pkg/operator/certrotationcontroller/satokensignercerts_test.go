@@ -0,0 +1,175 @@
+package certrotationcontroller
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/clock"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestUpdateSATokenSigningCerts(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	retention := 30 * 24 * time.Hour
+
+	type key struct {
+		pub, fingerprint string
+	}
+	newKey := func() key {
+		pub, priv, err := generateSATokenSigningKeyPair(SATokenSigningKeyECDSAP256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fingerprint, err := saTokenPrivateKeyPEMFingerprint(priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key{pub: string(pub), fingerprint: fingerprint}
+	}
+	retiredKey, oldKey, currentKey, nextKey := newKey(), newKey(), newKey(), newKey()
+
+	tests := []struct {
+		name            string
+		data            map[string]string
+		retired         map[string]string
+		inUse           []string
+		revoked         []string
+		expectedData    map[string]string
+		expectedRetired map[string]string
+		expectedPruned  int
+	}{
+		{
+			name: "index keys are replaced by fingerprints",
+			data: map[string]string{
+				"service-account-001.pub": currentKey.pub,
+				"service-account-002.pub": currentKey.pub,
+			},
+			inUse: []string{currentKey.fingerprint},
+			expectedData: map[string]string{
+				saTokenSigningCertsKey(currentKey.fingerprint): currentKey.pub,
+				saTokenSigningCertsKey(nextKey.fingerprint):    nextKey.pub,
+			},
+			expectedRetired: map[string]string{},
+		},
+		{
+			name: "keys no longer in use are retired",
+			data: map[string]string{
+				saTokenSigningCertsKey(oldKey.fingerprint):     oldKey.pub,
+				saTokenSigningCertsKey(currentKey.fingerprint): currentKey.pub,
+			},
+			inUse: []string{currentKey.fingerprint},
+			expectedData: map[string]string{
+				saTokenSigningCertsKey(oldKey.fingerprint):     oldKey.pub,
+				saTokenSigningCertsKey(currentKey.fingerprint): currentKey.pub,
+				saTokenSigningCertsKey(nextKey.fingerprint):    nextKey.pub,
+			},
+			expectedRetired: map[string]string{oldKey.fingerprint: now.Format(time.RFC3339)},
+		},
+		{
+			name: "keys retired for longer than the retention are pruned",
+			data: map[string]string{
+				saTokenSigningCertsKey(retiredKey.fingerprint): retiredKey.pub,
+				saTokenSigningCertsKey(oldKey.fingerprint):     oldKey.pub,
+				saTokenSigningCertsKey(currentKey.fingerprint): currentKey.pub,
+			},
+			retired: map[string]string{
+				retiredKey.fingerprint: now.Add(-31 * 24 * time.Hour).Format(time.RFC3339),
+				oldKey.fingerprint:     now.Add(-29 * 24 * time.Hour).Format(time.RFC3339),
+			},
+			inUse: []string{currentKey.fingerprint},
+			expectedData: map[string]string{
+				saTokenSigningCertsKey(oldKey.fingerprint):     oldKey.pub,
+				saTokenSigningCertsKey(currentKey.fingerprint): currentKey.pub,
+				saTokenSigningCertsKey(nextKey.fingerprint):    nextKey.pub,
+			},
+			expectedRetired: map[string]string{oldKey.fingerprint: now.Add(-29 * 24 * time.Hour).Format(time.RFC3339)},
+			expectedPruned:  1,
+		},
+		{
+			name: "keys in use again are no longer retired",
+			data: map[string]string{
+				saTokenSigningCertsKey(currentKey.fingerprint): currentKey.pub,
+			},
+			retired: map[string]string{
+				currentKey.fingerprint: now.Add(-60 * 24 * time.Hour).Format(time.RFC3339),
+				retiredKey.fingerprint: now.Add(-60 * 24 * time.Hour).Format(time.RFC3339),
+			},
+			inUse: []string{currentKey.fingerprint},
+			expectedData: map[string]string{
+				saTokenSigningCertsKey(currentKey.fingerprint): currentKey.pub,
+				saTokenSigningCertsKey(nextKey.fingerprint):    nextKey.pub,
+			},
+			expectedRetired: map[string]string{},
+		},
+		{
+			name: "revoked keys are removed right away",
+			data: map[string]string{
+				saTokenSigningCertsKey(oldKey.fingerprint):     oldKey.pub,
+				saTokenSigningCertsKey(currentKey.fingerprint): currentKey.pub,
+			},
+			retired: map[string]string{oldKey.fingerprint: now.Add(-time.Hour).Format(time.RFC3339)},
+			revoked: []string{oldKey.fingerprint, currentKey.fingerprint},
+			expectedData: map[string]string{
+				saTokenSigningCertsKey(nextKey.fingerprint): nextKey.pub,
+			},
+			expectedRetired: map[string]string{},
+		},
+		{
+			name: "unparseable entries are kept",
+			data: map[string]string{
+				"custom.pub": "not a key",
+			},
+			expectedData: map[string]string{
+				"custom.pub": "not a key",
+				saTokenSigningCertsKey(nextKey.fingerprint): nextKey.pub,
+			},
+			expectedRetired: map[string]string{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			existing := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "sa-token-signing-certs"},
+				Data:       test.data,
+			}
+			if test.retired != nil {
+				raw, err := json.Marshal(test.retired)
+				if err != nil {
+					t.Fatal(err)
+				}
+				existing.Annotations = map[string]string{saTokenRetiredKeysAnnotation: string(raw)}
+			}
+			recorder := events.NewInMemoryRecorder("satokensigner", clock.RealClock{})
+
+			certs, err := updateSATokenSigningCerts(recorder, existing, nextKey.pub, sets.New(test.inUse...), sets.New(test.revoked...), retention, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(test.expectedData, certs.Data) {
+				t.Errorf("expected data %v, got %v", test.expectedData, certs.Data)
+			}
+			retired := map[string]string{}
+			if err := json.Unmarshal([]byte(certs.Annotations[saTokenRetiredKeysAnnotation]), &retired); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(test.expectedRetired, retired) {
+				t.Errorf("expected retired %v, got %v", test.expectedRetired, retired)
+			}
+			pruned := 0
+			for _, event := range recorder.Events() {
+				if event.Reason == "SATokenSigningPublicKeyPruned" {
+					pruned++
+				}
+			}
+			if pruned != test.expectedPruned {
+				t.Errorf("expected %d prune events, got %d", test.expectedPruned, pruned)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package certrotationcontroller
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/keyutil"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/operatorclient"
+)
+
+const (
+	// SATokenSigningJWKSConfigMapName in openshift-config-managed publishes the public keys of sa-token-signing-certs
+	// as a JSON Web Key Set, for systems verifying service account tokens outside of the cluster.
+	SATokenSigningJWKSConfigMapName = "sa-token-signing-jwks"
+	// SATokenSigningJWKSConfigMapKey holds the JSON Web Key Set.
+	SATokenSigningJWKSConfigMapKey = "jwks.json"
+)
+
+// jsonWebKey is a public key as served by the kube-apiserver in its service account issuer discovery.
+type jsonWebKey struct {
+	Use       string `json:"use"`
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+
+	// RSA keys
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// ECDSA keys
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+	Y     string `json:"y,omitempty"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// newJSONWebKey returns the JWK of the public key. The kid is computed like the kube-apiserver and the
+// kube-controller-manager do for the tokens they sign.
+func newJSONWebKey(publicKey interface{}) (jsonWebKey, error) {
+	fingerprint, err := saTokenPublicKeyFingerprint(publicKey)
+	if err != nil {
+		return jsonWebKey{}, err
+	}
+	keyID, err := saTokenKeyID(fingerprint)
+	if err != nil {
+		return jsonWebKey{}, err
+	}
+	key := jsonWebKey{Use: "sig", KeyID: keyID}
+
+	switch publicKey := publicKey.(type) {
+	case *rsa.PublicKey:
+		key.KeyType = "RSA"
+		key.Algorithm = "RS256"
+		key.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+		key.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+	case *ecdsa.PublicKey:
+		key.KeyType = "EC"
+		switch publicKey.Curve {
+		case elliptic.P256():
+			key.Curve, key.Algorithm = "P-256", "ES256"
+		case elliptic.P384():
+			key.Curve, key.Algorithm = "P-384", "ES384"
+		case elliptic.P521():
+			key.Curve, key.Algorithm = "P-521", "ES512"
+		default:
+			return jsonWebKey{}, fmt.Errorf("unsupported ecdsa curve %s", publicKey.Curve.Params().Name)
+		}
+		// the coordinates are padded to the size of the curve
+		size := (publicKey.Curve.Params().BitSize + 7) / 8
+		key.X = base64.RawURLEncoding.EncodeToString(publicKey.X.FillBytes(make([]byte, size)))
+		key.Y = base64.RawURLEncoding.EncodeToString(publicKey.Y.FillBytes(make([]byte, size)))
+	default:
+		return jsonWebKey{}, fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+	return key, nil
+}
+
+// saTokenSigningJWKS returns the configmap publishing the public keys of the sa-token-signing-certs as a JSON Web Key
+// Set, ordered by kid. Entries that cannot be parsed are left out.
+func saTokenSigningJWKS(certs *corev1.ConfigMap) (*corev1.ConfigMap, error) {
+	keySet := jsonWebKeySet{Keys: []jsonWebKey{}}
+	keyIDs := sets.New[string]()
+	for _, name := range sets.List(sets.KeySet(certs.Data)) {
+		publicKeys, err := keyutil.ParsePublicKeysPEM([]byte(certs.Data[name]))
+		if err != nil {
+			klog.Warningf("Leaving %s of configmap/%s out of the JSON Web Key Set: %v", name, certs.Name, err)
+			continue
+		}
+		for _, publicKey := range publicKeys {
+			key, err := newJSONWebKey(publicKey)
+			if err != nil {
+				klog.Warningf("Leaving %s of configmap/%s out of the JSON Web Key Set: %v", name, certs.Name, err)
+				continue
+			}
+			if keyIDs.Has(key.KeyID) {
+				continue
+			}
+			keyIDs.Insert(key.KeyID)
+			keySet.Keys = append(keySet.Keys, key)
+		}
+	}
+	sort.Slice(keySet.Keys, func(i, j int) bool { return keySet.Keys[i].KeyID < keySet.Keys[j].KeyID })
+
+	raw, err := json.Marshal(keySet)
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.GlobalMachineSpecifiedConfigNamespace, Name: SATokenSigningJWKSConfigMapName},
+		Data:       map[string]string{SATokenSigningJWKSConfigMapKey: string(raw)},
+	}, nil
+}
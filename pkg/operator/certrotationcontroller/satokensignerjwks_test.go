@@ -0,0 +1,101 @@
+package certrotationcontroller
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/keyutil"
+)
+
+func TestSATokenSigningJWKS(t *testing.T) {
+	certs := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "sa-token-signing-certs"},
+		Data:       map[string]string{"custom.pub": "not a key"},
+	}
+	publicKeys := map[string]interface{}{}
+	for _, algorithm := range []SATokenSigningKeyAlgorithm{SATokenSigningKeyRSA, SATokenSigningKeyECDSAP256, SATokenSigningKeyECDSAP384} {
+		pub, _, err := generateSATokenSigningKeyPair(algorithm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fingerprint, err := saTokenPublicKeyPEMFingerprint(pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyID, err := saTokenKeyID(fingerprint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := keyutil.ParsePublicKeysPEM(pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		publicKeys[keyID] = parsed[0]
+		certs.Data[saTokenSigningCertsKey(fingerprint)] = string(pub)
+		// a key published twice is listed once
+		certs.Data["service-account-"+string(algorithm)+".pub"] = string(pub)
+	}
+
+	jwks, err := saTokenSigningJWKS(certs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jwks.Namespace != "openshift-config-managed" || jwks.Name != SATokenSigningJWKSConfigMapName {
+		t.Errorf("unexpected configmap %s/%s", jwks.Namespace, jwks.Name)
+	}
+	keySet := jsonWebKeySet{}
+	if err := json.Unmarshal([]byte(jwks.Data[SATokenSigningJWKSConfigMapKey]), &keySet); err != nil {
+		t.Fatal(err)
+	}
+	if len(keySet.Keys) != len(publicKeys) {
+		t.Fatalf("expected %d keys, got %d", len(publicKeys), len(keySet.Keys))
+	}
+
+	decode := func(s string) *big.Int {
+		raw, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return new(big.Int).SetBytes(raw)
+	}
+	for _, key := range keySet.Keys {
+		publicKey, ok := publicKeys[key.KeyID]
+		if !ok {
+			t.Errorf("unexpected kid %s", key.KeyID)
+			continue
+		}
+		if key.Use != "sig" {
+			t.Errorf("kid %s: expected use sig, got %s", key.KeyID, key.Use)
+		}
+		switch publicKey := publicKey.(type) {
+		case *rsa.PublicKey:
+			if key.KeyType != "RSA" || key.Algorithm != "RS256" {
+				t.Errorf("kid %s: unexpected kty %s and alg %s", key.KeyID, key.KeyType, key.Algorithm)
+			}
+			if !publicKey.Equal(&rsa.PublicKey{N: decode(key.N), E: int(decode(key.E).Int64())}) {
+				t.Errorf("kid %s: n and e do not match the public key", key.KeyID)
+			}
+		case *ecdsa.PublicKey:
+			expectedCurve, expectedAlgorithm, size := "P-256", "ES256", 32
+			if publicKey.Curve == elliptic.P384() {
+				expectedCurve, expectedAlgorithm, size = "P-384", "ES384", 48
+			}
+			if key.KeyType != "EC" || key.Curve != expectedCurve || key.Algorithm != expectedAlgorithm {
+				t.Errorf("kid %s: unexpected kty %s, crv %s and alg %s", key.KeyID, key.KeyType, key.Curve, key.Algorithm)
+			}
+			if x, _ := base64.RawURLEncoding.DecodeString(key.X); len(x) != size {
+				t.Errorf("kid %s: expected x of %d bytes, got %d", key.KeyID, size, len(x))
+			}
+			if !publicKey.Equal(&ecdsa.PublicKey{Curve: publicKey.Curve, X: decode(key.X), Y: decode(key.Y)}) {
+				t.Errorf("kid %s: x and y do not match the public key", key.KeyID)
+			}
+		}
+	}
+}
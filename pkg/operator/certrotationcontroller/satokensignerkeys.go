@@ -0,0 +1,183 @@
+package certrotationcontroller
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/util/keyutil"
+
+	"github.com/openshift/library-go/pkg/operator/encryption/crypto"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/operatorclient"
+)
+
+const (
+	// SATokenSigningConfigMapName is the configmap in openshift-config configuring the service account token signing key.
+	SATokenSigningConfigMapName = "kube-controller-manager-sa-token-signing"
+	// SATokenSigningConfigMapKey holds a SATokenSigningConfig.
+	SATokenSigningConfigMapKey = "sa-token-signing.yaml"
+
+	// minSATokenSigningKeyRotationInterval keeps every key in use long enough for the kube-apiservers to trust its
+	// successor, each rotation rolls out a new kube-apiserver revision.
+	minSATokenSigningKeyRotationInterval = 24 * time.Hour
+
+	// defaultSATokenPublicKeyRetention is the longest lifetime the kube-apiserver grants bound tokens, with their
+	// expiration extended.
+	defaultSATokenPublicKeyRetention = 365 * 24 * time.Hour
+	// minSATokenPublicKeyRetention keeps the tokens signed right before a rotation valid for a day at least.
+	minSATokenPublicKeyRetention = 24 * time.Hour
+)
+
+// SATokenSigningKeyAlgorithm is the algorithm of the service account token signing key. The kube-apiserver only
+// verifies RSA and ECDSA signed tokens, there is no Ed25519 support.
+type SATokenSigningKeyAlgorithm string
+
+const (
+	// SATokenSigningKeyRSA signs with RS256, the default.
+	SATokenSigningKeyRSA SATokenSigningKeyAlgorithm = "RSA"
+	// SATokenSigningKeyECDSAP256 signs with ES256.
+	SATokenSigningKeyECDSAP256 SATokenSigningKeyAlgorithm = "ECDSA-P256"
+	// SATokenSigningKeyECDSAP384 signs with ES384.
+	SATokenSigningKeyECDSAP384 SATokenSigningKeyAlgorithm = "ECDSA-P384"
+)
+
+// SATokenSigningConfig configures the service account token signing key. Unset fields keep their defaults.
+type SATokenSigningConfig struct {
+	// KeyAlgorithm of new signing keys. Changing it replaces the next signing key, the public keys of the previous ones
+	// stay published so that their tokens keep being accepted.
+	KeyAlgorithm SATokenSigningKeyAlgorithm `json:"keyAlgorithm,omitempty"`
+	// RotationInterval is the age after which the signing key in use is replaced. Unset, keys are only replaced when
+	// they are invalid or the KeyAlgorithm changed.
+	RotationInterval *metav1.Duration `json:"rotationInterval,omitempty"`
+	// PublicKeyRetention is how long the public key of a retired signing key stays published, tokens signed with it
+	// are rejected afterwards.
+	PublicKeyRetention *metav1.Duration `json:"publicKeyRetention,omitempty"`
+}
+
+// publicKeyRetention returns the configured retention of retired public keys or the default.
+func (c *SATokenSigningConfig) publicKeyRetention() time.Duration {
+	if c == nil || c.PublicKeyRetention == nil {
+		return defaultSATokenPublicKeyRetention
+	}
+	return c.PublicKeyRetention.Duration
+}
+
+// readSATokenSigningConfig reads the config from openshift-config, defaulting when the configmap is absent.
+func readSATokenSigningConfig(ctx context.Context, client corev1client.ConfigMapsGetter) (*SATokenSigningConfig, error) {
+	config := &SATokenSigningConfig{KeyAlgorithm: SATokenSigningKeyRSA}
+	configMap, err := client.ConfigMaps(operatorclient.GlobalUserSpecifiedConfigNamespace).Get(ctx, SATokenSigningConfigMapName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if raw := configMap.Data[SATokenSigningConfigMapKey]; len(strings.TrimSpace(raw)) > 0 {
+		configJSON, err := yaml.YAMLToJSON([]byte(raw))
+		if err != nil {
+			return nil, fmt.Errorf("configmap/%s in %s: invalid %s: %w", SATokenSigningConfigMapName, operatorclient.GlobalUserSpecifiedConfigNamespace, SATokenSigningConfigMapKey, err)
+		}
+		// unknown fields are rejected so that typos do not go unnoticed
+		decoder := json.NewDecoder(bytes.NewReader(configJSON))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(config); err != nil {
+			return nil, fmt.Errorf("configmap/%s in %s: invalid %s: %w", SATokenSigningConfigMapName, operatorclient.GlobalUserSpecifiedConfigNamespace, SATokenSigningConfigMapKey, err)
+		}
+	}
+	if len(config.KeyAlgorithm) == 0 {
+		config.KeyAlgorithm = SATokenSigningKeyRSA
+	}
+
+	switch config.KeyAlgorithm {
+	case SATokenSigningKeyRSA, SATokenSigningKeyECDSAP256, SATokenSigningKeyECDSAP384:
+	default:
+		return nil, fmt.Errorf("configmap/%s in %s: unsupported keyAlgorithm %q, must be one of %s, %s or %s", SATokenSigningConfigMapName, operatorclient.GlobalUserSpecifiedConfigNamespace,
+			config.KeyAlgorithm, SATokenSigningKeyRSA, SATokenSigningKeyECDSAP256, SATokenSigningKeyECDSAP384)
+	}
+	if config.RotationInterval != nil && config.RotationInterval.Duration < minSATokenSigningKeyRotationInterval {
+		return nil, fmt.Errorf("configmap/%s in %s: rotationInterval %s must be at least %s", SATokenSigningConfigMapName, operatorclient.GlobalUserSpecifiedConfigNamespace,
+			config.RotationInterval.Duration, minSATokenSigningKeyRotationInterval)
+	}
+	if config.PublicKeyRetention != nil && config.PublicKeyRetention.Duration < minSATokenPublicKeyRetention {
+		return nil, fmt.Errorf("configmap/%s in %s: publicKeyRetention %s must be at least %s", SATokenSigningConfigMapName, operatorclient.GlobalUserSpecifiedConfigNamespace,
+			config.PublicKeyRetention.Duration, minSATokenPublicKeyRetention)
+	}
+	return config, nil
+}
+
+// generateSATokenSigningKeyPair returns the PEM encoded public and private keys of a new signing key.
+func generateSATokenSigningKeyPair(algorithm SATokenSigningKeyAlgorithm) ([]byte, []byte, error) {
+	var curve elliptic.Curve
+	switch algorithm {
+	case SATokenSigningKeyRSA:
+		return crypto.GenerateRSAKeyPair()
+	case SATokenSigningKeyECDSAP256:
+		curve = elliptic.P256()
+	case SATokenSigningKeyECDSAP384:
+		curve = elliptic.P384()
+	default:
+		return nil, nil, fmt.Errorf("unsupported key algorithm %q", algorithm)
+	}
+
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	publicKeyDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	privateKeyPEM, err := keyutil.MarshalPrivateKeyToPEM(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyDER}), privateKeyPEM, nil
+}
+
+// checkSATokenSigningKeyPair validates that the keys match and returns their algorithm.
+func checkSATokenSigningKeyPair(publicKeyPEM, privateKeyPEM []byte) (SATokenSigningKeyAlgorithm, error) {
+	privateKey, err := keyutil.ParsePrivateKeyPEM(privateKeyPEM)
+	if err != nil {
+		return "", err
+	}
+	switch key := privateKey.(type) {
+	case *rsa.PrivateKey:
+		return SATokenSigningKeyRSA, crypto.CheckRSAKeyPair(publicKeyPEM, privateKeyPEM)
+	case *ecdsa.PrivateKey:
+		publicKeys, err := keyutil.ParsePublicKeysPEM(publicKeyPEM)
+		if err != nil {
+			return "", err
+		}
+		publicKey, ok := publicKeys[0].(*ecdsa.PublicKey)
+		if !ok {
+			return "", fmt.Errorf("public key is not of ecdsa type")
+		}
+		if !key.PublicKey.Equal(publicKey) {
+			return "", fmt.Errorf("key pair do not match")
+		}
+		switch key.Curve {
+		case elliptic.P256():
+			return SATokenSigningKeyECDSAP256, nil
+		case elliptic.P384():
+			return SATokenSigningKeyECDSAP384, nil
+		}
+		return "", fmt.Errorf("unsupported ecdsa curve %s", key.Curve.Params().Name)
+	default:
+		return "", fmt.Errorf("unsupported private key type %T", privateKey)
+	}
+}
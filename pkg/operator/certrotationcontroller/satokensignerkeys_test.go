@@ -0,0 +1,101 @@
+package certrotationcontroller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/operatorclient"
+)
+
+func TestSATokenSigningKeyPair(t *testing.T) {
+	for _, algorithm := range []SATokenSigningKeyAlgorithm{SATokenSigningKeyRSA, SATokenSigningKeyECDSAP256, SATokenSigningKeyECDSAP384} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			pub, priv, err := generateSATokenSigningKeyPair(algorithm)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual, err := checkSATokenSigningKeyPair(pub, priv)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != algorithm {
+				t.Errorf("expected %s, got %s", algorithm, actual)
+			}
+
+			otherPub, _, err := generateSATokenSigningKeyPair(algorithm)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := checkSATokenSigningKeyPair(otherPub, priv); err == nil {
+				t.Errorf("expected mismatched key pair to be rejected")
+			}
+		})
+	}
+}
+
+func TestReadSATokenSigningConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      *string
+		expected    SATokenSigningKeyAlgorithm
+		expectedErr string
+	}{
+		{
+			name:     "no configmap",
+			expected: SATokenSigningKeyRSA,
+		},
+		{
+			name:     "empty config",
+			config:   stringPtr(""),
+			expected: SATokenSigningKeyRSA,
+		},
+		{
+			name:     "ecdsa",
+			config:   stringPtr("keyAlgorithm: ECDSA-P384"),
+			expected: SATokenSigningKeyECDSAP384,
+		},
+		{
+			name:        "ed25519",
+			config:      stringPtr("keyAlgorithm: Ed25519"),
+			expectedErr: `unsupported keyAlgorithm "Ed25519"`,
+		},
+		{
+			name:        "unknown field",
+			config:      stringPtr("algorithm: RSA"),
+			expectedErr: "invalid sa-token-signing.yaml",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			if test.config != nil {
+				client = fake.NewSimpleClientset(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: SATokenSigningConfigMapName, Namespace: operatorclient.GlobalUserSpecifiedConfigNamespace},
+					Data:       map[string]string{SATokenSigningConfigMapKey: *test.config},
+				})
+			}
+			config, err := readSATokenSigningConfig(context.TODO(), client.CoreV1())
+			switch {
+			case len(test.expectedErr) == 0 && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case len(test.expectedErr) > 0:
+				if err == nil || !strings.Contains(err.Error(), test.expectedErr) {
+					t.Fatalf("expected error %q, got %v", test.expectedErr, err)
+				}
+				return
+			}
+			if config.KeyAlgorithm != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, config.KeyAlgorithm)
+			}
+		})
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
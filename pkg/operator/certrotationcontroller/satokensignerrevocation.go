@@ -0,0 +1,173 @@
+package certrotationcontroller
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/operatorclient"
+)
+
+const (
+	// RevokeSATokenSigningKeyAnnotation on the kubecontrollermanager/cluster resource requests the revocation of the
+	// service account signing key in use, e.g. after its private key leaked. Its value identifies the request, setting
+	// a new value revokes the key in use again.
+	RevokeSATokenSigningKeyAnnotation = "kube-controller-manager.openshift.io/revoke-service-account-signing-key"
+
+	// SATokenRevocationConfigMapName in the operator namespace lists the public keys revoked by the last request and
+	// the legacy token secrets signed with them.
+	SATokenRevocationConfigMapName = "service-account-signing-key-revocation"
+
+	// saTokenRevocationRequestAnnotation on the next-service-account-private-key records the request the key was
+	// generated for. On the revocation configmap, it records the request the token secrets were listed for.
+	saTokenRevocationRequestAnnotation = "kube-controller-manager.openshift.io/revocation-request"
+	// saTokenRevokedKeysAnnotation on the next-service-account-private-key lists the fingerprints of the keys it
+	// replaces, separated by commas.
+	saTokenRevokedKeysAnnotation = "kube-controller-manager.openshift.io/revoked-public-keys"
+)
+
+// saTokenRevocationRequested returns the id of the revocation requested on the operator resource.
+func (c *SATokenSignerController) saTokenRevocationRequested() (string, error) {
+	meta, err := c.operatorClient.GetObjectMeta()
+	if err != nil {
+		return "", err
+	}
+	return meta.Annotations[RevokeSATokenSigningKeyAnnotation], nil
+}
+
+// saTokenRevokedFingerprints returns the fingerprints of the keys replaced by the key of the secret when it was
+// generated for a revocation.
+func saTokenRevokedFingerprints(secret *corev1.Secret) sets.Set[string] {
+	revoked := sets.New[string]()
+	if secret == nil {
+		return revoked
+	}
+	for _, fingerprint := range strings.Split(secret.Annotations[saTokenRevokedKeysAnnotation], ",") {
+		if len(fingerprint) > 0 {
+			revoked.Insert(fingerprint)
+		}
+	}
+	return revoked
+}
+
+// saTokenKeyID returns the kid the kube-controller-manager and kube-apiserver set in the header of the tokens they
+// sign, the unpadded base64url encoded SHA-256 of the DER encoded public key.
+func saTokenKeyID(fingerprint string) (string, error) {
+	sum, err := hex.DecodeString(fingerprint)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(sum), nil
+}
+
+// saTokenSigningKeyID returns the kid in the header of the token.
+func saTokenSigningKeyID(token string) string {
+	header, _, _ := strings.Cut(token, ".")
+	raw, err := base64.RawURLEncoding.DecodeString(header)
+	if err != nil {
+		return ""
+	}
+	decoded := struct {
+		KeyID string `json:"kid"`
+	}{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return ""
+	}
+	return decoded.KeyID
+}
+
+// reportRevokedTokenSecrets lists the legacy token secrets signed with the revoked keys in the revocation configmap,
+// once per request. The kube-apiserver rejects their tokens, they must be generated again.
+func (c *SATokenSignerController) reportRevokedTokenSecrets(ctx context.Context, recorder events.Recorder, requestID string, revoked sets.Set[string]) error {
+	report, err := c.configMapClient.ConfigMaps(operatorclient.OperatorNamespace).Get(ctx, SATokenRevocationConfigMapName, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if err == nil && report.Annotations[saTokenRevocationRequestAnnotation] == requestID {
+		return nil
+	}
+
+	keyIDs := sets.New[string]()
+	for _, fingerprint := range revoked.UnsortedList() {
+		keyID, err := saTokenKeyID(fingerprint)
+		if err != nil {
+			return fmt.Errorf("revoked public key %q: %w", fingerprint, err)
+		}
+		keyIDs.Insert(keyID)
+	}
+	tokenSecrets, err := c.tokenSecretClient.Secrets(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("type", string(corev1.SecretTypeServiceAccountToken)).String(),
+	})
+	if err != nil {
+		return err
+	}
+	affected := sets.New[string]()
+	for _, secret := range tokenSecrets.Items {
+		if secret.Type != corev1.SecretTypeServiceAccountToken {
+			continue
+		}
+		if keyIDs.Has(saTokenSigningKeyID(string(secret.Data[corev1.ServiceAccountTokenKey]))) {
+			affected.Insert(secret.Namespace + "/" + secret.Name)
+		}
+	}
+
+	report = &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   operatorclient.OperatorNamespace,
+			Name:        SATokenRevocationConfigMapName,
+			Annotations: map[string]string{saTokenRevocationRequestAnnotation: requestID},
+		},
+		Data: map[string]string{
+			"revoked-public-keys":    strings.Join(sets.List(revoked), "\n"),
+			"affected-token-secrets": strings.Join(sets.List(affected), "\n"),
+		},
+	}
+	if _, _, err := resourceapply.ApplyConfigMap(ctx, c.configMapClient, recorder, report); err != nil {
+		return err
+	}
+	recorder.Eventf("SATokenSigningKeyRevocationCompleted", "Revoked the service account signing keys %s for request %q, %d legacy token secrets signed with them are listed in configmap/%s in %s",
+		strings.Join(sets.List(revoked), ", "), requestID, affected.Len(), SATokenRevocationConfigMapName, operatorclient.OperatorNamespace)
+	return nil
+}
+
+// revocationCondition reports the progress of the revocation requested on the operator resource.
+func (c *SATokenSignerController) revocationCondition(ctx context.Context) operatorv1.OperatorCondition {
+	condition := operatorv1.OperatorCondition{
+		Type:   "SATokenSigningKeyRevocationPending",
+		Status: operatorv1.ConditionFalse,
+		Reason: "AsExpected",
+	}
+	requestID, err := c.saTokenRevocationRequested()
+	if err != nil || len(requestID) == 0 {
+		return condition
+	}
+
+	report, err := c.configMapClient.ConfigMaps(operatorclient.OperatorNamespace).Get(ctx, SATokenRevocationConfigMapName, metav1.GetOptions{})
+	if err != nil || report.Annotations[saTokenRevocationRequestAnnotation] != requestID {
+		condition.Status = operatorv1.ConditionTrue
+		condition.Reason = "Revoking"
+		condition.Message = fmt.Sprintf("Revoking the service account signing key for request %q", requestID)
+		return condition
+	}
+	affected := 0
+	if secrets := report.Data["affected-token-secrets"]; len(secrets) > 0 {
+		affected = len(strings.Split(secrets, "\n"))
+	}
+	condition.Reason = "Completed"
+	condition.Message = fmt.Sprintf("The service account signing key was revoked for request %q. %d legacy token secrets signed with the revoked keys are listed in configmap/%s in %s and must be generated again.",
+		requestID, affected, SATokenRevocationConfigMapName, operatorclient.OperatorNamespace)
+	return condition
+}
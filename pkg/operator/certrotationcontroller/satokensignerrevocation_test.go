@@ -0,0 +1,149 @@
+package certrotationcontroller
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/clock"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/operatorclient"
+)
+
+// fakeOperatorMeta serves the metadata of the operator resource, the fake static pod operator client does not.
+type fakeOperatorMeta struct {
+	v1helpers.StaticPodOperatorClient
+	meta *metav1.ObjectMeta
+}
+
+func (f *fakeOperatorMeta) GetObjectMeta() (*metav1.ObjectMeta, error) {
+	return f.meta, nil
+}
+
+// bootstrappedOperatorClient returns an operator client of a cluster whose bootstrap node is gone.
+func bootstrappedOperatorClient(annotations map[string]string) *fakeOperatorMeta {
+	return &fakeOperatorMeta{
+		StaticPodOperatorClient: v1helpers.NewFakeStaticPodOperatorClient(
+			&operatorv1.StaticPodOperatorSpec{},
+			&operatorv1.StaticPodOperatorStatus{OperatorStatus: operatorv1.OperatorStatus{
+				Conditions: []operatorv1.OperatorCondition{{Type: bootstrapNodeGoneCondition, Status: operatorv1.ConditionTrue}},
+			}},
+			nil, nil,
+		),
+		meta: &metav1.ObjectMeta{Annotations: annotations},
+	}
+}
+
+func TestSATokenSigningKeyRevocation(t *testing.T) {
+	pub, priv, err := generateSATokenSigningKeyPair(SATokenSigningKeyRSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fingerprint, err := saTokenPublicKeyPEMFingerprint(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyID, err := saTokenKeyID(fingerprint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := func(namespace, name string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace, Name: name,
+				Annotations: map[string]string{saTokenGeneratedAtAnnotation: time.Now().Add(-24 * time.Hour).Format(time.RFC3339)},
+			},
+			Data: map[string][]byte{"service-account.key": priv, "service-account.pub": pub},
+		}
+	}
+	tokenSecret := func(namespace, name, kid string) *corev1.Secret {
+		header := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"alg":"RS256","kid":%q}`, kid)))
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Type:       corev1.SecretTypeServiceAccountToken,
+			Data:       map[string][]byte{corev1.ServiceAccountTokenKey: []byte(header + ".e30.c2ln")},
+		}
+	}
+
+	kubeClient := fake.NewSimpleClientset(
+		key(operatorclient.OperatorNamespace, "next-service-account-private-key"),
+		key(operatorclient.TargetNamespace, "service-account-private-key"),
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.GlobalMachineSpecifiedConfigNamespace, Name: "sa-token-signing-certs"},
+			Data:       map[string]string{saTokenSigningCertsKey(fingerprint): string(pub)},
+		},
+		tokenSecret("app", "builder-token", keyID),
+		tokenSecret("app", "deployer-token", "other"),
+	)
+	c := &SATokenSignerController{
+		operatorClient:  bootstrappedOperatorClient(map[string]string{RevokeSATokenSigningKeyAnnotation: "leak-1"}),
+		secretClient:    kubeClient.CoreV1(),
+		configMapClient: kubeClient.CoreV1(),
+		// a revoked key is replaced without waiting for the kube-apiservers
+		kubeAPIServerClient:          &fakeKubeAPIServers{kubeAPIServer: &operatorv1.KubeAPIServer{}},
+		kubeAPIServerConfigMapClient: kubeClient.CoreV1(),
+		tokenSecretClient:            kubeClient.CoreV1(),
+	}
+	recorder := events.NewInMemoryRecorder("satokensigner", clock.RealClock{})
+	syncCtx := factory.NewSyncContext("SATokenSignerController", recorder)
+
+	if err := c.syncWorker(context.TODO(), syncCtx); err != nil {
+		t.Fatal(err)
+	}
+	next, err := kubeClient.CoreV1().Secrets(operatorclient.OperatorNamespace).Get(context.TODO(), "next-service-account-private-key", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(next.Data["service-account.pub"]) == string(pub) {
+		t.Fatalf("expected the revoked key to be replaced")
+	}
+	if next.Annotations[saTokenRevocationRequestAnnotation] != "leak-1" || next.Annotations[saTokenRevokedKeysAnnotation] != fingerprint {
+		t.Errorf("expected the revocation to be recorded on the new key, got %v", next.Annotations)
+	}
+	current, err := kubeClient.CoreV1().Secrets(operatorclient.TargetNamespace).Get(context.TODO(), "service-account-private-key", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(current.Data["service-account.pub"]) != string(next.Data["service-account.pub"]) {
+		t.Errorf("expected the new key to be promoted right away")
+	}
+	certs, err := kubeClient.CoreV1().ConfigMaps(operatorclient.GlobalMachineSpecifiedConfigNamespace).Get(context.TODO(), "sa-token-signing-certs", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := certs.Data[saTokenSigningCertsKey(fingerprint)]; ok || len(certs.Data) != 1 {
+		t.Errorf("expected only the new public key to be published, got %v", certs.Data)
+	}
+	report, err := kubeClient.CoreV1().ConfigMaps(operatorclient.OperatorNamespace).Get(context.TODO(), SATokenRevocationConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Data["affected-token-secrets"] != "app/builder-token" || report.Data["revoked-public-keys"] != fingerprint {
+		t.Errorf("unexpected report %v", report.Data)
+	}
+	if condition := c.revocationCondition(context.TODO()); condition.Reason != "Completed" {
+		t.Errorf("expected the revocation to be completed, got %#v", condition)
+	}
+
+	// the request is handled once
+	if err := c.syncWorker(context.TODO(), syncCtx); err != nil {
+		t.Fatal(err)
+	}
+	again, err := kubeClient.CoreV1().Secrets(operatorclient.OperatorNamespace).Get(context.TODO(), "next-service-account-private-key", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again.Data["service-account.key"]) != string(next.Data["service-account.key"]) {
+		t.Errorf("expected the key not to be replaced again")
+	}
+}
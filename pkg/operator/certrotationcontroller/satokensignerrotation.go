@@ -0,0 +1,118 @@
+package certrotationcontroller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/operatorclient"
+)
+
+const (
+	// saTokenGeneratedAtAnnotation records when a signing key was generated, it is copied along when the key is
+	// promoted to the service-account-private-key.
+	saTokenGeneratedAtAnnotation = "kube-controller-manager.openshift.io/generated-at"
+
+	// kubeAPIServerNamespace holds the revisioned sa-token-signing-certs configmaps of the kube-apiservers.
+	kubeAPIServerNamespace = "openshift-kube-apiserver"
+)
+
+// saTokenSigningKeyGeneratedAt returns when the key of the secret was generated. Keys generated before the time was
+// recorded fall back to the creation of their secret.
+func saTokenSigningKeyGeneratedAt(secret *corev1.Secret) time.Time {
+	if generatedAt, err := time.Parse(time.RFC3339, secret.Annotations[saTokenGeneratedAtAnnotation]); err == nil {
+		return generatedAt
+	}
+	return secret.CreationTimestamp.Time
+}
+
+// saTokenSigningKeyRotationDue returns whether the key in use is older than the rotation interval. A next key that is
+// not promoted yet is never replaced by a scheduled rotation.
+func saTokenSigningKeyRotationDue(config *SATokenSigningConfig, next, current *corev1.Secret, now time.Time) bool {
+	if config == nil || config.RotationInterval == nil || current == nil {
+		return false
+	}
+	if string(next.Data["service-account.pub"]) != string(current.Data["service-account.pub"]) {
+		return false
+	}
+	return !now.Before(saTokenSigningKeyGeneratedAt(current).Add(config.RotationInterval.Duration))
+}
+
+// kubeAPIServersTrust returns whether every kube-apiserver runs a revision whose sa-token-signing-certs contain the
+// public key, and why not otherwise.
+func (c *SATokenSignerController) kubeAPIServersTrust(ctx context.Context, publicKey string) (bool, string, error) {
+	kubeAPIServer, err := c.kubeAPIServerClient.KubeAPIServers().Get(ctx, "cluster", metav1.GetOptions{})
+	if err != nil {
+		return false, "", err
+	}
+	if len(kubeAPIServer.Status.NodeStatuses) == 0 {
+		return false, "no kube-apiserver reports its revision", nil
+	}
+	for _, nodeStatus := range kubeAPIServer.Status.NodeStatuses {
+		if nodeStatus.CurrentRevision == 0 {
+			return false, fmt.Sprintf("the kube-apiserver on node %s runs no revision yet", nodeStatus.NodeName), nil
+		}
+		name := fmt.Sprintf("sa-token-signing-certs-%d", nodeStatus.CurrentRevision)
+		certs, err := c.kubeAPIServerConfigMapClient.ConfigMaps(kubeAPIServerNamespace).Get(ctx, name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return false, fmt.Sprintf("configmap/%s in %s of the kube-apiserver on node %s is missing", name, kubeAPIServerNamespace, nodeStatus.NodeName), nil
+		}
+		if err != nil {
+			return false, "", err
+		}
+		if !configMapHasValue(certs, publicKey) {
+			return false, fmt.Sprintf("the kube-apiserver on node %s runs revision %d which does not trust the key yet", nodeStatus.NodeName, nodeStatus.CurrentRevision), nil
+		}
+	}
+	return true, "", nil
+}
+
+// rotationCondition reports when the signing key in use was generated and when it is rotated next.
+func (c *SATokenSignerController) rotationCondition(ctx context.Context) operatorv1.OperatorCondition {
+	condition := operatorv1.OperatorCondition{
+		Type:   "SATokenSigningKeyRotation",
+		Status: operatorv1.ConditionFalse,
+		Reason: "NotScheduled",
+	}
+
+	current, err := c.secretClient.Secrets(operatorclient.TargetNamespace).Get(ctx, "service-account-private-key", metav1.GetOptions{})
+	if err != nil {
+		condition.Reason = "Unknown"
+		condition.Message = fmt.Sprintf("The service account signing key in use cannot be read: %v", err)
+		return condition
+	}
+	generatedAt := saTokenSigningKeyGeneratedAt(current)
+	condition.Message = fmt.Sprintf("The service account signing key in use was generated at %s.", generatedAt.UTC().Format(time.RFC3339))
+
+	config, err := readSATokenSigningConfig(ctx, c.configMapClient)
+	if err != nil || config.RotationInterval == nil {
+		condition.Message += fmt.Sprintf(" Set a rotationInterval in configmap/%s in %s to rotate it on a schedule.", SATokenSigningConfigMapName, operatorclient.GlobalUserSpecifiedConfigNamespace)
+		return condition
+	}
+	condition.Status = operatorv1.ConditionTrue
+	condition.Reason = "Scheduled"
+
+	next, err := c.secretClient.Secrets(operatorclient.OperatorNamespace).Get(ctx, "next-service-account-private-key", metav1.GetOptions{})
+	if err == nil && string(next.Data["service-account.pub"]) != string(current.Data["service-account.pub"]) {
+		condition.Reason = "WaitingForTrust"
+		condition.Message += fmt.Sprintf(" The key generated at %s is used once all kube-apiservers trust it.", saTokenSigningKeyGeneratedAt(next).UTC().Format(time.RFC3339))
+		return condition
+	}
+	condition.Message += fmt.Sprintf(" The next rotation is at %s.", generatedAt.Add(config.RotationInterval.Duration).UTC().Format(time.RFC3339))
+	return condition
+}
+
+func configMapHasValue(configMap *corev1.ConfigMap, value string) bool {
+	for _, v := range configMap.Data {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
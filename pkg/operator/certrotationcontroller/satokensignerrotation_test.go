@@ -0,0 +1,250 @@
+package certrotationcontroller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/clock"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	operatorv1client "github.com/openshift/client-go/operator/clientset/versioned/typed/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/operatorclient"
+)
+
+func TestSATokenSigningKeyRotationDue(t *testing.T) {
+	now := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	key := func(pub string, generatedAt time.Time) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{saTokenGeneratedAtAnnotation: generatedAt.Format(time.RFC3339)}},
+			Data:       map[string][]byte{"service-account.pub": []byte(pub)},
+		}
+	}
+	weekly := &SATokenSigningConfig{KeyAlgorithm: SATokenSigningKeyRSA, RotationInterval: &metav1.Duration{Duration: 7 * 24 * time.Hour}}
+
+	tests := []struct {
+		name          string
+		config        *SATokenSigningConfig
+		next, current *corev1.Secret
+		expected      bool
+	}{
+		{
+			name:    "no rotation interval",
+			config:  &SATokenSigningConfig{KeyAlgorithm: SATokenSigningKeyRSA},
+			next:    key("a", now.Add(-30*24*time.Hour)),
+			current: key("a", now.Add(-30*24*time.Hour)),
+		},
+		{
+			name:    "key in use is younger than the interval",
+			config:  weekly,
+			next:    key("a", now.Add(-6*24*time.Hour)),
+			current: key("a", now.Add(-6*24*time.Hour)),
+		},
+		{
+			name:     "key in use is older than the interval",
+			config:   weekly,
+			next:     key("a", now.Add(-8*24*time.Hour)),
+			current:  key("a", now.Add(-8*24*time.Hour)),
+			expected: true,
+		},
+		{
+			name:    "next key is not promoted yet",
+			config:  weekly,
+			next:    key("b", now.Add(-time.Hour)),
+			current: key("a", now.Add(-8*24*time.Hour)),
+		},
+		{
+			name:   "key generated before the time was recorded",
+			config: weekly,
+			next: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(now.Add(-8 * 24 * time.Hour))},
+				Data:       map[string][]byte{"service-account.pub": []byte("a")},
+			},
+			current: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(now.Add(-8 * 24 * time.Hour))},
+				Data:       map[string][]byte{"service-account.pub": []byte("a")},
+			},
+			expected: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := saTokenSigningKeyRotationDue(test.config, test.next, test.current, now); actual != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+type fakeKubeAPIServers struct {
+	operatorv1client.KubeAPIServerInterface
+	kubeAPIServer *operatorv1.KubeAPIServer
+}
+
+func (f *fakeKubeAPIServers) KubeAPIServers() operatorv1client.KubeAPIServerInterface {
+	return f
+}
+
+func (f *fakeKubeAPIServers) Get(context.Context, string, metav1.GetOptions) (*operatorv1.KubeAPIServer, error) {
+	return f.kubeAPIServer, nil
+}
+
+func TestKubeAPIServersTrust(t *testing.T) {
+	revision := func(revision int32, keys ...string) *corev1.ConfigMap {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: kubeAPIServerNamespace, Name: fmt.Sprintf("sa-token-signing-certs-%d", revision)},
+			Data:       map[string]string{},
+		}
+		for i, key := range keys {
+			cm.Data[fmt.Sprintf("service-account-%03d.pub", i+1)] = key
+		}
+		return cm
+	}
+	nodes := func(revisions ...int32) *operatorv1.KubeAPIServer {
+		kas := &operatorv1.KubeAPIServer{}
+		for i, revision := range revisions {
+			kas.Status.NodeStatuses = append(kas.Status.NodeStatuses, operatorv1.NodeStatus{NodeName: fmt.Sprintf("master-%d", i), CurrentRevision: revision})
+		}
+		return kas
+	}
+
+	tests := []struct {
+		name          string
+		kubeAPIServer *operatorv1.KubeAPIServer
+		configMaps    []*corev1.ConfigMap
+		expected      bool
+	}{
+		{
+			name:          "all revisions trust the key",
+			kubeAPIServer: nodes(3, 3, 3),
+			configMaps:    []*corev1.ConfigMap{revision(3, "old", "new")},
+			expected:      true,
+		},
+		{
+			name:          "a node runs an older revision",
+			kubeAPIServer: nodes(3, 2, 3),
+			configMaps:    []*corev1.ConfigMap{revision(2, "old"), revision(3, "old", "new")},
+		},
+		{
+			name:          "revision configmap is missing",
+			kubeAPIServer: nodes(4),
+			configMaps:    []*corev1.ConfigMap{revision(3, "old", "new")},
+		},
+		{
+			name:          "no node status",
+			kubeAPIServer: nodes(),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kubeClient := fake.NewSimpleClientset()
+			for _, cm := range test.configMaps {
+				if _, err := kubeClient.CoreV1().ConfigMaps(cm.Namespace).Create(context.TODO(), cm, metav1.CreateOptions{}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			c := &SATokenSignerController{
+				kubeAPIServerClient:          &fakeKubeAPIServers{kubeAPIServer: test.kubeAPIServer},
+				kubeAPIServerConfigMapClient: kubeClient.CoreV1(),
+			}
+			trusted, reason, err := c.kubeAPIServersTrust(context.TODO(), "new")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if trusted != test.expected {
+				t.Errorf("expected %v, got %v: %s", test.expected, trusted, reason)
+			}
+			if !trusted && len(reason) == 0 {
+				t.Errorf("expected a reason")
+			}
+		})
+	}
+}
+
+func TestSATokenSignerPromotion(t *testing.T) {
+	pub, priv, err := generateSATokenSigningKeyPair(SATokenSigningKeyRSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nextKey := func(generatedAt time.Time) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: operatorclient.OperatorNamespace, Name: "next-service-account-private-key",
+				Annotations: map[string]string{saTokenGeneratedAtAnnotation: generatedAt.Format(time.RFC3339)},
+			},
+			Data: map[string][]byte{"service-account.key": priv, "service-account.pub": pub},
+		}
+	}
+	revision := func(keys ...string) *corev1.ConfigMap {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: kubeAPIServerNamespace, Name: "sa-token-signing-certs-3"},
+			Data:       map[string]string{},
+		}
+		for i, key := range keys {
+			cm.Data[fmt.Sprintf("service-account-%03d.pub", i+1)] = key
+		}
+		return cm
+	}
+
+	kubeAPIServer := &operatorv1.KubeAPIServer{}
+	kubeAPIServer.Status.NodeStatuses = []operatorv1.NodeStatus{{NodeName: "master-0", CurrentRevision: 3}}
+
+	tests := []struct {
+		name             string
+		next             *corev1.Secret
+		revision         *corev1.ConfigMap
+		expectedPromoted bool
+		expectedErr      string
+	}{
+		{
+			name:             "trusted by all kube-apiservers",
+			next:             nextKey(time.Now()),
+			revision:         revision("old", string(pub)),
+			expectedPromoted: true,
+		},
+		{
+			name:     "not trusted yet",
+			next:     nextKey(time.Now().Add(-10 * time.Minute)),
+			revision: revision("old"),
+		},
+		{
+			name:        "not trusted after the timeout",
+			next:        nextKey(time.Now().Add(-2 * time.Hour)),
+			revision:    revision("old"),
+			expectedErr: "is not trusted by all kube-apiservers after 1h0m0s",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kubeClient := fake.NewSimpleClientset(test.next, test.revision)
+			c := &SATokenSignerController{
+				operatorClient:               bootstrappedOperatorClient(nil),
+				secretClient:                 kubeClient.CoreV1(),
+				configMapClient:              kubeClient.CoreV1(),
+				kubeAPIServerClient:          &fakeKubeAPIServers{kubeAPIServer: kubeAPIServer},
+				kubeAPIServerConfigMapClient: kubeClient.CoreV1(),
+			}
+			syncCtx := factory.NewSyncContext("SATokenSignerController", events.NewInMemoryRecorder("satokensigner", clock.RealClock{}))
+			err := c.syncWorker(context.TODO(), syncCtx)
+			switch {
+			case len(test.expectedErr) == 0 && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case len(test.expectedErr) > 0 && (err == nil || !strings.Contains(err.Error(), test.expectedErr)):
+				t.Fatalf("expected error %q, got %v", test.expectedErr, err)
+			}
+
+			current, err := kubeClient.CoreV1().Secrets(operatorclient.TargetNamespace).Get(context.TODO(), "service-account-private-key", metav1.GetOptions{})
+			if promoted := err == nil && string(current.Data["service-account.pub"]) == string(pub); promoted != test.expectedPromoted {
+				t.Errorf("expected promoted %v, got %v", test.expectedPromoted, promoted)
+			}
+		})
+	}
+}
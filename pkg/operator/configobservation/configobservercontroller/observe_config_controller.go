@@ -2,7 +2,10 @@ package configobservercontroller
 
 import (
 	configv1 "github.com/openshift/api/config/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/metadata/metadatainformer"
+	"k8s.io/client-go/metadata/metadatalister"
 	"k8s.io/client-go/tools/cache"
 
 	configinformers "github.com/openshift/client-go/config/informers/externalversions"
@@ -19,9 +22,13 @@ import (
 
 	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/configobservation"
 	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/configobservation/clustername"
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/configobservation/containerlogging"
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/configobservation/controllers"
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/configobservation/csrsigning"
 	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/configobservation/network"
 	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/configobservation/node"
 	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/configobservation/serviceca"
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/configobservation/sizing"
 	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/operatorclient"
 )
 
@@ -31,6 +38,26 @@ import (
 // excluding them from the feature gate output passed to KCM.
 var openShiftOnlyFeatureGates = sets.New[configv1.FeatureGateName]()
 
+// ExtendedArgumentObservers maps the extendedArguments set by the observers below to the observer setting them.
+// It is used to explain where a kube-controller-manager flag came from and must be kept in sync with the observers.
+var ExtendedArgumentObservers = map[string]string{
+	"cluster-name":              "ObserveInfraID",
+	"feature-gates":             "ObserveFeatureFlags",
+	"cluster-cidr":              "ObserveClusterCIDRs",
+	"service-cluster-ip-range":  "ObserveServiceClusterIPRanges",
+	"node-monitor-grace-period": "LatencyProfileObserver",
+	"controllers":               "ObserveControllers",
+	"cluster-signing-duration":  "ObserveCSRSigning",
+}
+
+func init() {
+	for _, tier := range append([]sizing.Tier{sizing.SingleReplicaTier}, sizing.Tiers...) {
+		for name := range tier.ExtendedArguments {
+			ExtendedArgumentObservers[name] = "ObserveClusterSizing"
+		}
+	}
+}
+
 type ConfigObserver struct {
 	factory.Controller
 }
@@ -39,6 +66,7 @@ func NewConfigObserver(
 	operatorClient v1helpers.OperatorClient,
 	configinformers configinformers.SharedInformerFactory,
 	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	metadataInformers metadatainformer.SharedInformerFactory,
 	resourceSyncer resourcesynccontroller.ResourceSyncer,
 	featureGateAccessor featuregates.FeatureGateAccess,
 	eventRecorder events.Recorder,
@@ -67,6 +95,11 @@ func NewConfigObserver(
 		informers = append(informers, kubeInformersForNamespaces.InformersFor(ns).Core().V1().ConfigMaps().Informer())
 	}
 
+	nodesResource := corev1.SchemeGroupVersion.WithResource("nodes")
+	namespacesResource := corev1.SchemeGroupVersion.WithResource("namespaces")
+	nodeMetadataInformer := metadataInformers.ForResource(nodesResource).Informer()
+	namespaceMetadataInformer := metadataInformers.ForResource(namespacesResource).Informer()
+
 	extremeProfileSuppressor, err := nodeobserver.NewSuppressConfigUpdateForExtremeProfilesFunc(
 		operatorClient.(v1helpers.StaticPodOperatorClient),
 		configinformers.Config().V1().Nodes().Lister(),
@@ -96,6 +129,10 @@ func NewConfigObserver(
 				ProxyLister_:          configinformers.Config().V1().Proxies().Lister(),
 				APIServerLister_:      configinformers.Config().V1().APIServers().Lister(),
 
+				// nodes and namespaces are not watched, the cluster size is picked up by the periodic resync
+				KubeNodeLister_:  metadatalister.New(nodeMetadataInformer.GetIndexer(), nodesResource),
+				NamespaceLister_: metadatalister.New(namespaceMetadataInformer.GetIndexer(), namespacesResource),
+
 				ResourceSync:     resourceSyncer,
 				ConfigMapLister_: kubeInformersForNamespaces.ConfigMapLister(),
 				PreRunCachesSynced: append(configMapPreRunCacheSynced,
@@ -109,6 +146,8 @@ func NewConfigObserver(
 					configinformers.Config().V1().Networks().Informer().HasSynced,
 					configinformers.Config().V1().Nodes().Informer().HasSynced,
 					configinformers.Config().V1().Proxies().Informer().HasSynced,
+					nodeMetadataInformer.HasSynced,
+					namespaceMetadataInformer.HasSynced,
 				),
 			},
 			informers,
@@ -148,6 +187,10 @@ func NewConfigObserver(
 			proxy.NewProxyObserveFunc([]string{"targetconfigcontroller", "proxy"}),
 			serviceca.ObserveServiceCA,
 			clustername.ObserveInfraID,
+			controllers.ObserveControllers,
+			containerlogging.ObserveContainerLogging,
+			csrsigning.NewObserveCSRSigningFunc(featureGateAccessor),
+			sizing.ObserveClusterSizing,
 			libgoapiserver.ObserveTLSSecurityProfile,
 		),
 	}
@@ -0,0 +1,175 @@
+package containerlogging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/bindata"
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/operatorclient"
+)
+
+const (
+	// ConfigMapName is the configmap in openshift-config setting the logging of the kube-controller-manager containers.
+	ConfigMapName = "kube-controller-manager-logging"
+	// ConfigMapKey holds the ContainerLogging of every configured container by container name.
+	ConfigMapKey = "logging.yaml"
+
+	// MaxVerbosity is the highest klog verbosity the operator log levels map to (TraceAll).
+	MaxVerbosity = 10
+)
+
+var (
+	loggingPath = []string{"targetconfigcontroller", "logging"}
+
+	// vmodulePattern matches the klog file patterns, a glob on the source file name or path.
+	vmodulePattern = regexp.MustCompile(`^[A-Za-z0-9_.*?/-]+$`)
+	// vmoduleLevel matches an unsigned verbosity.
+	vmoduleLevel = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// ContainerLogging overrides the logging of a single container of the kube-controller-manager pod, e.g.
+//
+//	kube-controller-manager:
+//	  verbosity: 4
+//	  vmodule: garbagecollector*=6,node_lifecycle_controller*=5
+type ContainerLogging struct {
+	// Verbosity replaces the verbosity derived from the operator log level.
+	Verbosity *int `json:"verbosity,omitempty"`
+	// VModule is passed to the container as --vmodule.
+	VModule string `json:"vmodule,omitempty"`
+}
+
+// ObserveContainerLogging fills in targetconfigcontroller.logging from the kube-controller-manager-logging configmap in
+// openshift-config.
+func ObserveContainerLogging(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (map[string]interface{}, []error) {
+	listers := genericListers.(configobservation.Listers)
+	errs := []error{}
+	previouslyObservedConfig := map[string]interface{}{}
+
+	if currentLogging, _, _ := unstructured.NestedMap(existingConfig, loggingPath...); len(currentLogging) > 0 {
+		if err := unstructured.SetNestedMap(previouslyObservedConfig, currentLogging, loggingPath...); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	observedConfig := map[string]interface{}{}
+	configMap, err := listers.ConfigMapLister().ConfigMaps(operatorclient.GlobalUserSpecifiedConfigNamespace).Get(ConfigMapName)
+	if errors.IsNotFound(err) {
+		// the containers log with the verbosity of the operator log level
+		return observedConfig, errs
+	}
+	if err != nil {
+		return previouslyObservedConfig, append(errs, err)
+	}
+
+	config, err := ReadConfig([]byte(configMap.Data[ConfigMapKey]))
+	if err == nil {
+		err = Validate("", config)
+	}
+	if err != nil {
+		return previouslyObservedConfig, append(errs, fmt.Errorf("configmap/%s in %s: invalid %s: %w", ConfigMapName, operatorclient.GlobalUserSpecifiedConfigNamespace, ConfigMapKey, err))
+	}
+	if len(config) == 0 {
+		return observedConfig, errs
+	}
+
+	// round trip through json, so that the observed config holds the same types as the one read back from the operator
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return previouslyObservedConfig, append(errs, err)
+	}
+	logging := map[string]interface{}{}
+	if err := json.Unmarshal(configJSON, &logging); err != nil {
+		return previouslyObservedConfig, append(errs, err)
+	}
+	if err := unstructured.SetNestedMap(observedConfig, logging, loggingPath...); err != nil {
+		errs = append(errs, err)
+	}
+
+	if !equality.Semantic.DeepEqual(previouslyObservedConfig, observedConfig) {
+		recorder.Eventf("ObserveContainerLogging", "container logging changed to %s", configJSON)
+	}
+	return observedConfig, errs
+}
+
+// ReadConfig decodes the logging of the containers, rejecting unknown fields so that typos do not go unnoticed.
+func ReadConfig(raw []byte) (map[string]ContainerLogging, error) {
+	ret := map[string]ContainerLogging{}
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return ret, nil
+	}
+	configJSON, err := yaml.YAMLToJSON(raw)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(configJSON))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Validate checks that the logging is set for containers of the kube-controller-manager pod and holds valid klog
+// settings. Errors are reported for path.<container name>.
+func Validate(path string, config map[string]ContainerLogging) error {
+	pod := resourceread.ReadPodV1OrDie(bindata.MustAsset("assets/kube-controller-manager/pod.yaml"))
+	containers := map[string]bool{}
+	for _, container := range pod.Spec.Containers {
+		containers[container.Name] = true
+	}
+
+	errs := []error{}
+	for name, logging := range config {
+		field := name
+		if len(path) > 0 {
+			field = path + "." + name
+		}
+		if !containers[name] {
+			errs = append(errs, fmt.Errorf("%s: unknown container", field))
+			continue
+		}
+		if logging.Verbosity != nil && (*logging.Verbosity < 0 || *logging.Verbosity > MaxVerbosity) {
+			errs = append(errs, fmt.Errorf("%s.verbosity: must be between 0 and %d, got %d", field, MaxVerbosity, *logging.Verbosity))
+		}
+		if err := validateVModule(logging.VModule); err != nil {
+			errs = append(errs, fmt.Errorf("%s.vmodule: %w", field, err))
+		}
+	}
+	// map iteration order is random, keep the message stable
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return utilerrors.NewAggregate(errs)
+}
+
+// validateVModule checks a klog --vmodule value, a comma separated list of pattern=N.
+func validateVModule(vmodule string) error {
+	if len(vmodule) == 0 {
+		return nil
+	}
+	for _, setting := range strings.Split(vmodule, ",") {
+		pattern, level, found := strings.Cut(setting, "=")
+		if !found || !vmodulePattern.MatchString(pattern) {
+			return fmt.Errorf("invalid pattern=N setting %q", setting)
+		}
+		if v, err := strconv.Atoi(level); !vmoduleLevel.MatchString(level) || err != nil || v > MaxVerbosity {
+			return fmt.Errorf("invalid verbosity %q for %s, must be between 0 and %d", level, pattern, MaxVerbosity)
+		}
+	}
+	return nil
+}
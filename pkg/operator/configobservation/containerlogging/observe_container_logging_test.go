@@ -0,0 +1,118 @@
+package containerlogging
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/clock"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/operatorclient"
+)
+
+func TestObserveContainerLogging(t *testing.T) {
+	previous := map[string]interface{}{
+		"targetconfigcontroller": map[string]interface{}{
+			"logging": map[string]interface{}{
+				"kube-controller-manager": map[string]interface{}{"verbosity": float64(4)},
+			},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		config          *string
+		input, expected map[string]interface{}
+		expectedErr     string
+	}{
+		{
+			name:     "no configmap",
+			input:    previous,
+			expected: map[string]interface{}{},
+		},
+		{
+			name:     "empty config",
+			config:   stringPtr(""),
+			input:    previous,
+			expected: map[string]interface{}{},
+		},
+		{
+			name:   "verbosity and vmodule",
+			config: stringPtr("kube-controller-manager:\n  verbosity: 4\n  vmodule: garbagecollector*=6\nkube-controller-manager-cert-syncer:\n  verbosity: 6"),
+			input:  map[string]interface{}{},
+			expected: map[string]interface{}{
+				"targetconfigcontroller": map[string]interface{}{
+					"logging": map[string]interface{}{
+						"kube-controller-manager":             map[string]interface{}{"verbosity": float64(4), "vmodule": "garbagecollector*=6"},
+						"kube-controller-manager-cert-syncer": map[string]interface{}{"verbosity": float64(6)},
+					},
+				},
+			},
+		},
+		{
+			name:        "unknown container",
+			config:      stringPtr("kcm:\n  verbosity: 4"),
+			input:       previous,
+			expected:    previous,
+			expectedErr: "kcm: unknown container",
+		},
+		{
+			name:        "verbosity out of range",
+			config:      stringPtr("cluster-policy-controller:\n  verbosity: 11"),
+			input:       previous,
+			expected:    previous,
+			expectedErr: "cluster-policy-controller.verbosity: must be between 0 and 10, got 11",
+		},
+		{
+			name:        "invalid vmodule",
+			config:      stringPtr("kube-controller-manager:\n  vmodule: $(id)=2"),
+			input:       previous,
+			expected:    previous,
+			expectedErr: `kube-controller-manager.vmodule: invalid pattern=N setting "$(id)=2"`,
+		},
+		{
+			name:        "invalid config",
+			config:      stringPtr("kube-controller-manager:\n  v: 4"),
+			input:       previous,
+			expected:    previous,
+			expectedErr: "invalid logging.yaml",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if test.config != nil {
+				if err := indexer.Add(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: ConfigMapName, Namespace: operatorclient.GlobalUserSpecifiedConfigNamespace},
+					Data:       map[string]string{ConfigMapKey: *test.config},
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			listers := configobservation.Listers{
+				ConfigMapLister_: corev1listers.NewConfigMapLister(indexer),
+			}
+			result, errs := ObserveContainerLogging(listers, events.NewInMemoryRecorder("containerlogging", clock.RealClock{}), test.input)
+			switch {
+			case len(test.expectedErr) == 0 && len(errs) > 0:
+				t.Fatalf("unexpected errors: %v", errs)
+			case len(test.expectedErr) > 0 && (len(errs) != 1 || !strings.Contains(errs[0].Error(), test.expectedErr)):
+				t.Fatalf("expected error %q, got %v", test.expectedErr, errs)
+			}
+			if !reflect.DeepEqual(test.expected, result) {
+				t.Errorf("expected %#v, got %#v", test.expected, result)
+			}
+		})
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,177 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/bindata"
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/operatorclient"
+)
+
+const (
+	// ConfigMapName is the configmap in openshift-config enabling and disabling individual controllers.
+	ConfigMapName = "kube-controller-manager-controllers"
+	// ConfigMapKey holds a ControllersConfig.
+	ConfigMapKey = "controllers.yaml"
+)
+
+var controllersPath = []string{"extendedArguments", "controllers"}
+
+// ControllersConfig enables and disables individual kube-controller-manager controllers on top of the defaults.
+type ControllersConfig struct {
+	Enable  []string `json:"enable"`
+	Disable []string `json:"disable"`
+}
+
+// KnownControllers describes the controllers of the kube-controller-manager shipped in the payload.
+type KnownControllers struct {
+	Controllers []struct {
+		Name    string   `json:"name"`
+		Aliases []string `json:"aliases"`
+	} `json:"controllers"`
+	Protected []string `json:"protected"`
+}
+
+// ObserveControllers fills in the controllers extended argument by applying the kube-controller-manager-controllers
+// configmap in openshift-config to the default controllers.
+func ObserveControllers(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (map[string]interface{}, []error) {
+	listers := genericListers.(configobservation.Listers)
+	errs := []error{}
+	previouslyObservedConfig := map[string]interface{}{}
+
+	if currentControllers, _, _ := unstructured.NestedStringSlice(existingConfig, controllersPath...); len(currentControllers) > 0 {
+		if err := unstructured.SetNestedStringSlice(previouslyObservedConfig, currentControllers, controllersPath...); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	observedConfig := map[string]interface{}{}
+	configMap, err := listers.ConfigMapLister().ConfigMaps(operatorclient.GlobalUserSpecifiedConfigNamespace).Get(ConfigMapName)
+	if errors.IsNotFound(err) {
+		// the defaults apply
+		return observedConfig, errs
+	}
+	if err != nil {
+		return previouslyObservedConfig, append(errs, err)
+	}
+
+	config, err := readControllersConfig(configMap.Data[ConfigMapKey])
+	if err != nil {
+		return previouslyObservedConfig, append(errs, fmt.Errorf("configmap/%s in %s: invalid %s: %w", ConfigMapName, operatorclient.GlobalUserSpecifiedConfigNamespace, ConfigMapKey, err))
+	}
+	if len(config.Enable) == 0 && len(config.Disable) == 0 {
+		return observedConfig, errs
+	}
+
+	controllers, err := applyControllersConfig(config)
+	if err != nil {
+		return previouslyObservedConfig, append(errs, fmt.Errorf("configmap/%s in %s: %w", ConfigMapName, operatorclient.GlobalUserSpecifiedConfigNamespace, err))
+	}
+	if err := unstructured.SetNestedStringSlice(observedConfig, controllers, controllersPath...); err != nil {
+		errs = append(errs, err)
+	}
+
+	if !equality.Semantic.DeepEqual(previouslyObservedConfig, observedConfig) {
+		recorder.Eventf("ObserveControllers", "controllers changed to %s", strings.Join(controllers, ","))
+	}
+	return observedConfig, errs
+}
+
+// readControllersConfig decodes the config, rejecting unknown fields so that typos do not go unnoticed.
+func readControllersConfig(raw string) (*ControllersConfig, error) {
+	config := &ControllersConfig{}
+	if len(strings.TrimSpace(raw)) == 0 {
+		return config, nil
+	}
+	configJSON, err := yaml.YAMLToJSON([]byte(raw))
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(configJSON))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// applyControllersConfig validates the config against the known controllers and applies it to the default controllers.
+func applyControllersConfig(config *ControllersConfig) ([]string, error) {
+	known := &KnownControllers{}
+	if err := yaml.Unmarshal(bindata.MustAsset("assets/config/kube-controller-manager-controllers.yaml"), known); err != nil {
+		return nil, err
+	}
+	// canonical maps every name and alias to the controller name
+	canonical := map[string]string{}
+	for _, controller := range known.Controllers {
+		canonical[controller.Name] = controller.Name
+		for _, alias := range controller.Aliases {
+			canonical[alias] = controller.Name
+		}
+	}
+
+	unknown := sets.New[string]()
+	enable := sets.New[string]()
+	for _, name := range config.Enable {
+		if _, ok := canonical[name]; !ok {
+			unknown.Insert(name)
+			continue
+		}
+		enable.Insert(canonical[name])
+	}
+	disable := sets.New[string]()
+	for _, name := range config.Disable {
+		if _, ok := canonical[name]; !ok {
+			unknown.Insert(name)
+			continue
+		}
+		disable.Insert(canonical[name])
+	}
+	if unknown.Len() > 0 {
+		return nil, fmt.Errorf("unknown controllers %s", strings.Join(sets.List(unknown), ", "))
+	}
+	if both := enable.Intersection(disable); both.Len() > 0 {
+		return nil, fmt.Errorf("controllers %s are both enabled and disabled", strings.Join(sets.List(both), ", "))
+	}
+	if protected := disable.Intersection(sets.New(known.Protected...)); protected.Len() > 0 {
+		return nil, fmt.Errorf("refusing to disable controllers the platform depends on: %s", strings.Join(sets.List(protected), ", "))
+	}
+
+	defaultConfig := map[string]interface{}{}
+	if err := yaml.Unmarshal(bindata.MustAsset("assets/config/defaultconfig.yaml"), &defaultConfig); err != nil {
+		return nil, err
+	}
+	defaultControllers, _, err := unstructured.NestedStringSlice(defaultConfig, controllersPath...)
+	if err != nil {
+		return nil, err
+	}
+
+	// drop the default entries of the changed controllers, then add them back enabled or disabled
+	controllers := []string{}
+	for _, entry := range defaultControllers {
+		name := canonical[strings.TrimPrefix(entry, "-")]
+		if enable.Has(name) || disable.Has(name) {
+			continue
+		}
+		controllers = append(controllers, entry)
+	}
+	for _, name := range sets.List(enable) {
+		controllers = append(controllers, name)
+	}
+	for _, name := range sets.List(disable) {
+		controllers = append(controllers, "-"+name)
+	}
+	return controllers, nil
+}
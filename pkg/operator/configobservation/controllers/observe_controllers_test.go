@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/clock"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/operatorclient"
+)
+
+func TestObserveControllers(t *testing.T) {
+	previous := map[string]interface{}{
+		"extendedArguments": map[string]interface{}{
+			"controllers": []interface{}{"*", "-ttl"},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		config          *string
+		input, expected map[string]interface{}
+		expectedErr     string
+	}{
+		{
+			name:     "no configmap",
+			input:    previous,
+			expected: map[string]interface{}{},
+		},
+		{
+			name:     "empty config",
+			config:   stringPtr(""),
+			input:    previous,
+			expected: map[string]interface{}{},
+		},
+		{
+			name:   "enable and disable",
+			config: stringPtr("enable: [ttl-controller, bootstrapsigner]\ndisable: [cronjob, horizontalpodautoscaling]"),
+			input:  map[string]interface{}{},
+			expected: map[string]interface{}{
+				"extendedArguments": map[string]interface{}{
+					"controllers": []interface{}{"*", "-tokencleaner", "selinux-warning-controller", "bootstrapsigner", "ttl", "-cronjob", "-horizontalpodautoscaling"},
+				},
+			},
+		},
+		{
+			name:        "unknown controller",
+			config:      stringPtr("disable: [cronjob, foo]"),
+			input:       previous,
+			expected:    previous,
+			expectedErr: "unknown controllers foo",
+		},
+		{
+			name:        "protected controller",
+			config:      stringPtr("disable: [certificatesigningrequest-signing-controller, garbagecollector]"),
+			input:       previous,
+			expected:    previous,
+			expectedErr: "refusing to disable controllers the platform depends on: csrsigning, garbagecollector",
+		},
+		{
+			name:        "enabled and disabled",
+			config:      stringPtr("enable: [ttl]\ndisable: [ttl-controller]"),
+			input:       previous,
+			expected:    previous,
+			expectedErr: "controllers ttl are both enabled and disabled",
+		},
+		{
+			name:        "invalid config",
+			config:      stringPtr("disabled: [ttl]"),
+			input:       previous,
+			expected:    previous,
+			expectedErr: "invalid controllers.yaml",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if test.config != nil {
+				if err := indexer.Add(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: ConfigMapName, Namespace: operatorclient.GlobalUserSpecifiedConfigNamespace},
+					Data:       map[string]string{ConfigMapKey: *test.config},
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			listers := configobservation.Listers{
+				ConfigMapLister_: corev1listers.NewConfigMapLister(indexer),
+			}
+			result, errs := ObserveControllers(listers, events.NewInMemoryRecorder("controllers", clock.RealClock{}), test.input)
+			switch {
+			case len(test.expectedErr) == 0 && len(errs) > 0:
+				t.Fatalf("unexpected errors: %v", errs)
+			case len(test.expectedErr) > 0 && (len(errs) != 1 || !strings.Contains(errs[0].Error(), test.expectedErr)):
+				t.Fatalf("expected error %q, got %v", test.expectedErr, errs)
+			}
+			if !reflect.DeepEqual(test.expected, result) {
+				t.Errorf("expected %#v, got %#v", test.expected, result)
+			}
+		})
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,218 @@
+package csrsigning
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	features "github.com/openshift/api/features"
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/operatorclient"
+)
+
+const (
+	// ConfigMapName is the configmap in openshift-config setting the lifetimes of the CSR signer and of the
+	// certificates it issues.
+	ConfigMapName = "kube-controller-manager-csr-signing"
+	// ConfigMapKey holds a Config.
+	ConfigMapKey = "csr-signing.yaml"
+
+	// DefaultRefreshRatio rotates the csr-signer halfway through its validity.
+	DefaultRefreshRatio = 0.5
+	// DefaultExpiryWarningWindow is how long before their expiry the signers are alerted on. It is shortened to fit
+	// short-lived csr-signers.
+	DefaultExpiryWarningWindow = 7 * 24 * time.Hour
+
+	// minSignerValidity leaves the new signers enough time to be trusted before they are used.
+	minSignerValidity = time.Hour
+	// maxRefreshRatio is where the cert rotation rotates regardless of the configured refresh.
+	maxRefreshRatio = 0.8
+)
+
+var signingDurationPath = []string{"extendedArguments", "cluster-signing-duration"}
+
+// Config sets the lifetimes of the CSR signer and of the certificates it issues. Unset fields keep their defaults.
+type Config struct {
+	// SignerValidity is the lifetime of the csr-signer. The csr-signer-signer issuing it is valid twice as long and is
+	// rotated once the SignerValidity passed.
+	SignerValidity *metav1.Duration `json:"signerValidity,omitempty"`
+	// RefreshRatio is the fraction of the SignerValidity after which the csr-signer is rotated.
+	RefreshRatio *float64 `json:"refreshRatio,omitempty"`
+	// IssuedCertificateDuration is the lifetime of the certificates signed by the kube-controller-manager, its
+	// cluster-signing-duration.
+	IssuedCertificateDuration *metav1.Duration `json:"issuedCertificateDuration,omitempty"`
+	// ExpiryWarningWindow is how long before their expiry the KubeControllerManagerSignerExpiringSoon alert fires for
+	// the signers that were not rotated.
+	ExpiryWarningWindow *metav1.Duration `json:"expiryWarningWindow,omitempty"`
+}
+
+// Settings are the lifetimes resolved from a Config.
+type Settings struct {
+	SignerValidity time.Duration
+	SignerRefresh  time.Duration
+	// IssuedCertificateDuration is zero when it is not configured, the cluster-signing-duration of the default config
+	// is kept then.
+	IssuedCertificateDuration time.Duration
+	ExpiryWarningWindow       time.Duration
+}
+
+// DefaultSignerValidity is the csr-signer validity without a Config, shortened for clusters testing the rotation.
+func DefaultSignerValidity(featureGates featuregates.FeatureGate) time.Duration {
+	// This featuregate should be enabled on install time, we don't support enabling or disabling it after install.
+	if featureGates.Enabled(features.FeatureShortCertRotation) {
+		return 2 * time.Hour
+	}
+	return 30 * 24 * time.Hour
+}
+
+// DefaultSettings are the lifetimes used without the configmap, resolved like an empty config.
+func DefaultSettings(signerValidity time.Duration) (Settings, error) {
+	return Resolve(&Config{}, signerValidity)
+}
+
+// ReadSettings resolves the settings from the configmap in openshift-config, or the default settings without it.
+func ReadSettings(lister corev1listers.ConfigMapLister, defaultSignerValidity time.Duration) (Settings, error) {
+	configMap, err := lister.ConfigMaps(operatorclient.GlobalUserSpecifiedConfigNamespace).Get(ConfigMapName)
+	if errors.IsNotFound(err) {
+		return DefaultSettings(defaultSignerValidity)
+	}
+	if err != nil {
+		return Settings{}, err
+	}
+	config, err := readConfig(configMap.Data[ConfigMapKey])
+	if err != nil {
+		return Settings{}, fmt.Errorf("configmap/%s in %s: invalid %s: %w", ConfigMapName, operatorclient.GlobalUserSpecifiedConfigNamespace, ConfigMapKey, err)
+	}
+	settings, err := Resolve(config, defaultSignerValidity)
+	if err != nil {
+		return Settings{}, fmt.Errorf("configmap/%s in %s: %w", ConfigMapName, operatorclient.GlobalUserSpecifiedConfigNamespace, err)
+	}
+	return settings, nil
+}
+
+// Resolve applies the config to the defaults and validates that the certificates issued by a csr-signer never
+// outlive it: a certificate issued right before the csr-signer is rotated must expire before the csr-signer does.
+func Resolve(config *Config, defaultSignerValidity time.Duration) (Settings, error) {
+	signerValidity := defaultSignerValidity
+	if config.SignerValidity != nil {
+		signerValidity = config.SignerValidity.Duration
+	}
+	if signerValidity < minSignerValidity {
+		return Settings{}, fmt.Errorf("signerValidity %s must be at least %s", signerValidity, minSignerValidity)
+	}
+
+	refreshRatio := DefaultRefreshRatio
+	if config.RefreshRatio != nil {
+		refreshRatio = *config.RefreshRatio
+	}
+	if refreshRatio <= 0 || refreshRatio > maxRefreshRatio {
+		return Settings{}, fmt.Errorf("refreshRatio %v must be greater than 0 and at most %v", refreshRatio, maxRefreshRatio)
+	}
+	signerRefresh := time.Duration(float64(signerValidity) * refreshRatio).Truncate(time.Second)
+	maxIssuedCertificateDuration := signerValidity - signerRefresh
+
+	var issuedCertificateDuration time.Duration
+	if config.IssuedCertificateDuration != nil {
+		issuedCertificateDuration = config.IssuedCertificateDuration.Duration
+		if issuedCertificateDuration <= 0 {
+			return Settings{}, fmt.Errorf("issuedCertificateDuration %s must be positive", issuedCertificateDuration)
+		}
+		if issuedCertificateDuration > maxIssuedCertificateDuration {
+			return Settings{}, fmt.Errorf("issuedCertificateDuration %s must be at most %s, certificates issued right before the csr-signer is rotated after %s would outlive its signerValidity of %s",
+				issuedCertificateDuration, maxIssuedCertificateDuration, signerRefresh, signerValidity)
+		}
+	}
+
+	// a csr-signer is rotated when this much of its lifetime is left, a window as long would alert on every rotation
+	expiryWarningWindow := DefaultExpiryWarningWindow
+	if expiryWarningWindow > maxIssuedCertificateDuration/2 {
+		expiryWarningWindow = maxIssuedCertificateDuration / 2
+	}
+	if config.ExpiryWarningWindow != nil {
+		expiryWarningWindow = config.ExpiryWarningWindow.Duration
+		if expiryWarningWindow <= 0 || expiryWarningWindow >= maxIssuedCertificateDuration {
+			return Settings{}, fmt.Errorf("expiryWarningWindow %s must be positive and shorter than the %s left of the csr-signer when it is rotated",
+				expiryWarningWindow, maxIssuedCertificateDuration)
+		}
+	}
+
+	return Settings{
+		SignerValidity:            signerValidity,
+		SignerRefresh:             signerRefresh,
+		IssuedCertificateDuration: issuedCertificateDuration,
+		ExpiryWarningWindow:       expiryWarningWindow,
+	}, nil
+}
+
+// NewObserveCSRSigningFunc returns an observer setting the cluster-signing-duration from the issuedCertificateDuration
+// of the kube-controller-manager-csr-signing configmap in openshift-config. Without it the cluster-signing-duration of
+// the default config is kept. The cert rotation reads the signer lifetimes from the same configmap.
+func NewObserveCSRSigningFunc(featureGateAccessor featuregates.FeatureGateAccess) configobserver.ObserveConfigFunc {
+	return func(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (map[string]interface{}, []error) {
+		listers := genericListers.(configobservation.Listers)
+		errs := []error{}
+		previouslyObservedConfig := map[string]interface{}{}
+
+		if currentDuration, _, _ := unstructured.NestedStringSlice(existingConfig, signingDurationPath...); len(currentDuration) > 0 {
+			if err := unstructured.SetNestedStringSlice(previouslyObservedConfig, currentDuration, signingDurationPath...); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		featureGates, err := featureGateAccessor.CurrentFeatureGates()
+		if err != nil {
+			return previouslyObservedConfig, append(errs, err)
+		}
+		settings, err := ReadSettings(listers.ConfigMapLister(), DefaultSignerValidity(featureGates))
+		if err != nil {
+			return previouslyObservedConfig, append(errs, err)
+		}
+
+		observedConfig := map[string]interface{}{}
+		if settings.IssuedCertificateDuration == 0 {
+			if len(previouslyObservedConfig) > 0 {
+				recorder.Eventf("ObserveCSRSigning", "cluster-signing-duration reset to the default")
+			}
+			return observedConfig, errs
+		}
+		if err := unstructured.SetNestedStringSlice(observedConfig, []string{settings.IssuedCertificateDuration.String()}, signingDurationPath...); err != nil {
+			errs = append(errs, err)
+		}
+
+		if !equality.Semantic.DeepEqual(previouslyObservedConfig, observedConfig) {
+			recorder.Eventf("ObserveCSRSigning", "cluster-signing-duration changed to %s", settings.IssuedCertificateDuration)
+		}
+		return observedConfig, errs
+	}
+}
+
+// readConfig decodes the config, rejecting unknown fields so that typos do not go unnoticed.
+func readConfig(raw string) (*Config, error) {
+	config := &Config{}
+	if len(strings.TrimSpace(raw)) == 0 {
+		return config, nil
+	}
+	configJSON, err := yaml.YAMLToJSON([]byte(raw))
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(configJSON))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
@@ -0,0 +1,225 @@
+package csrsigning
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/clock"
+
+	configv1 "github.com/openshift/api/config/v1"
+	features "github.com/openshift/api/features"
+	"github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/operatorclient"
+)
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name                  string
+		config                string
+		defaultSignerValidity time.Duration
+		expected              Settings
+		expectedErr           string
+	}{
+		{
+			name:                  "empty config",
+			defaultSignerValidity: 720 * time.Hour,
+			expected:              Settings{SignerValidity: 720 * time.Hour, SignerRefresh: 360 * time.Hour, ExpiryWarningWindow: 168 * time.Hour},
+		},
+		{
+			name:                  "short signer validity",
+			config:                "signerValidity: 48h\nrefreshRatio: 0.25",
+			defaultSignerValidity: 720 * time.Hour,
+			expected:              Settings{SignerValidity: 48 * time.Hour, SignerRefresh: 12 * time.Hour, ExpiryWarningWindow: 18 * time.Hour},
+		},
+		{
+			name:                  "issued certificate duration",
+			config:                "signerValidity: 2160h\nissuedCertificateDuration: 720h",
+			defaultSignerValidity: 720 * time.Hour,
+			expected:              Settings{SignerValidity: 2160 * time.Hour, SignerRefresh: 1080 * time.Hour, IssuedCertificateDuration: 720 * time.Hour, ExpiryWarningWindow: 168 * time.Hour},
+		},
+		{
+			name:                  "short cert rotation default",
+			config:                "refreshRatio: 0.5",
+			defaultSignerValidity: 2 * time.Hour,
+			expected:              Settings{SignerValidity: 2 * time.Hour, SignerRefresh: time.Hour, ExpiryWarningWindow: 30 * time.Minute},
+		},
+		{
+			name:                  "expiry warning window",
+			config:                "expiryWarningWindow: 72h",
+			defaultSignerValidity: 720 * time.Hour,
+			expected:              Settings{SignerValidity: 720 * time.Hour, SignerRefresh: 360 * time.Hour, ExpiryWarningWindow: 72 * time.Hour},
+		},
+		{
+			name:                  "expiry warning window longer than a rotation",
+			config:                "expiryWarningWindow: 360h",
+			defaultSignerValidity: 720 * time.Hour,
+			expectedErr:           "expiryWarningWindow 360h0m0s must be positive and shorter than the 360h0m0s left of the csr-signer when it is rotated",
+		},
+		{
+			name:                  "issued certificates outlive the signer",
+			config:                "signerValidity: 720h\nissuedCertificateDuration: 720h",
+			defaultSignerValidity: 720 * time.Hour,
+			expectedErr:           "issuedCertificateDuration 720h0m0s must be at most 360h0m0s",
+		},
+		{
+			name:                  "signer validity too short",
+			config:                "signerValidity: 30m",
+			defaultSignerValidity: 720 * time.Hour,
+			expectedErr:           "signerValidity 30m0s must be at least 1h0m0s",
+		},
+		{
+			name:                  "refresh ratio too large",
+			config:                "refreshRatio: 0.9",
+			defaultSignerValidity: 720 * time.Hour,
+			expectedErr:           "refreshRatio 0.9 must be greater than 0 and at most 0.8",
+		},
+		{
+			name:                  "negative issued certificate duration",
+			config:                "issuedCertificateDuration: -1h",
+			defaultSignerValidity: 720 * time.Hour,
+			expectedErr:           "issuedCertificateDuration -1h0m0s must be positive",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config, err := readConfig(test.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			settings, err := Resolve(config, test.defaultSignerValidity)
+			switch {
+			case len(test.expectedErr) == 0 && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case len(test.expectedErr) > 0 && (err == nil || !strings.Contains(err.Error(), test.expectedErr)):
+				t.Fatalf("expected error %q, got %v", test.expectedErr, err)
+			}
+			if settings != test.expected {
+				t.Errorf("expected %+v, got %+v", test.expected, settings)
+			}
+		})
+	}
+}
+
+func TestObserveCSRSigning(t *testing.T) {
+	previous := map[string]interface{}{
+		"extendedArguments": map[string]interface{}{
+			"cluster-signing-duration": []interface{}{"360h0m0s"},
+		},
+	}
+
+	tests := []struct {
+		name              string
+		config            *string
+		shortCertRotation bool
+		input, expected   map[string]interface{}
+		expectedErr       string
+	}{
+		{
+			name:     "no configmap",
+			input:    map[string]interface{}{},
+			expected: map[string]interface{}{},
+		},
+		{
+			name:     "empty configmap",
+			config:   stringPtr(""),
+			input:    map[string]interface{}{},
+			expected: map[string]interface{}{},
+		},
+		{
+			name:     "configmap removed",
+			input:    previous,
+			expected: map[string]interface{}{},
+		},
+		{
+			name:              "no configmap with short cert rotation",
+			shortCertRotation: true,
+			input:             map[string]interface{}{},
+			expected:          map[string]interface{}{},
+		},
+		{
+			name:     "signer lifetimes only",
+			config:   stringPtr("signerValidity: 2160h"),
+			input:    map[string]interface{}{},
+			expected: map[string]interface{}{},
+		},
+		{
+			name:   "issued certificate duration",
+			config: stringPtr("signerValidity: 4320h\nissuedCertificateDuration: 1440h"),
+			input:  map[string]interface{}{},
+			expected: map[string]interface{}{
+				"extendedArguments": map[string]interface{}{
+					"cluster-signing-duration": []interface{}{"1440h0m0s"},
+				},
+			},
+		},
+		{
+			name:              "short cert rotation",
+			config:            stringPtr("refreshRatio: 0.75\nissuedCertificateDuration: 30m"),
+			shortCertRotation: true,
+			input:             map[string]interface{}{},
+			expected: map[string]interface{}{
+				"extendedArguments": map[string]interface{}{
+					"cluster-signing-duration": []interface{}{"30m0s"},
+				},
+			},
+		},
+		{
+			name:        "invalid config",
+			config:      stringPtr("signerValidty: 24h"),
+			input:       previous,
+			expected:    previous,
+			expectedErr: "invalid csr-signing.yaml",
+		},
+		{
+			name:        "inconsistent config",
+			config:      stringPtr("issuedCertificateDuration: 8760h"),
+			input:       previous,
+			expected:    previous,
+			expectedErr: "certificates issued right before the csr-signer is rotated after 360h0m0s would outlive its signerValidity of 720h0m0s",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if test.config != nil {
+				if err := indexer.Add(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: ConfigMapName, Namespace: operatorclient.GlobalUserSpecifiedConfigNamespace},
+					Data:       map[string]string{ConfigMapKey: *test.config},
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			enabled, disabled := []configv1.FeatureGateName{}, []configv1.FeatureGateName{features.FeatureShortCertRotation}
+			if test.shortCertRotation {
+				enabled, disabled = disabled, enabled
+			}
+			listers := configobservation.Listers{
+				ConfigMapLister_: corev1listers.NewConfigMapLister(indexer),
+			}
+			observe := NewObserveCSRSigningFunc(featuregates.NewHardcodedFeatureGateAccess(enabled, disabled))
+			result, errs := observe(listers, events.NewInMemoryRecorder("csrsigning", clock.RealClock{}), test.input)
+			switch {
+			case len(test.expectedErr) == 0 && len(errs) > 0:
+				t.Fatalf("unexpected errors: %v", errs)
+			case len(test.expectedErr) > 0 && (len(errs) != 1 || !strings.Contains(errs[0].Error(), test.expectedErr)):
+				t.Fatalf("expected error %q, got %v", test.expectedErr, errs)
+			}
+			if !reflect.DeepEqual(test.expected, result) {
+				t.Errorf("expected %#v, got %#v", test.expected, result)
+			}
+		})
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
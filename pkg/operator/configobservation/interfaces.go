@@ -2,6 +2,7 @@ package configobservation
 
 import (
 	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/metadata/metadatalister"
 	"k8s.io/client-go/tools/cache"
 
 	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
@@ -19,6 +20,9 @@ type Listers struct {
 	ProxyLister_          configlistersv1.ProxyLister
 	ConfigMapLister_      corev1listers.ConfigMapLister
 	APIServerLister_      configlistersv1.APIServerLister
+	// nodes and namespaces are only counted, their listers hold the object metadata only
+	KubeNodeLister_  metadatalister.Lister
+	NamespaceLister_ metadatalister.Lister
 
 	ResourceSync       resourcesynccontroller.ResourceSyncer
 	PreRunCachesSynced []cache.InformerSynced
@@ -55,3 +59,11 @@ func (l Listers) ConfigMapLister() corev1listers.ConfigMapLister {
 func (l Listers) APIServerLister() configlistersv1.APIServerLister {
 	return l.APIServerLister_
 }
+
+func (l Listers) KubeNodeLister() metadatalister.Lister {
+	return l.KubeNodeLister_
+}
+
+func (l Listers) NamespaceLister() metadatalister.Lister {
+	return l.NamespaceLister_
+}
@@ -0,0 +1,208 @@
+package sizing
+
+import (
+	"fmt"
+	"math"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/configobservation"
+)
+
+// TierPath is where the selected sizing tier is recorded in the observed config. The targetconfigcontroller exposes
+// it in the ClusterSizing operator condition.
+var TierPath = []string{"targetconfigcontroller", "sizing", "tier"}
+
+// Tier is a set of kube-controller-manager tunings for clusters of a given size.
+type Tier struct {
+	Name string
+	// MinNodes and MinNamespaces select the tier when either is reached. A tier without them is never selected by size.
+	MinNodes      int
+	MinNamespaces int
+	// ExtendedArguments are the kube-controller-manager flags set for the tier, on top of the default config.
+	ExtendedArguments map[string]string
+}
+
+const (
+	TierSingleReplica = "SingleReplica"
+	TierDefault       = "Default"
+	TierLarge         = "Large"
+	TierXLarge        = "XLarge"
+)
+
+// scaleDownMargin is the fraction of the thresholds of the current tier a cluster must shrink below before a smaller
+// tier is selected. Every tier change rolls out a new revision, so the tier must not flap around a threshold.
+const scaleDownMargin = 0.8
+
+// SingleReplicaTier is used for single-node control planes regardless of their size.
+var SingleReplicaTier = Tier{
+	Name: TierSingleReplica,
+	ExtendedArguments: map[string]string{
+		"kube-api-qps":                "100",
+		"kube-api-burst":              "200",
+		"concurrent-deployment-syncs": "2",
+		"concurrent-replicaset-syncs": "2",
+		"concurrent-gc-syncs":         "10",
+		"concurrent-namespace-syncs":  "5",
+	},
+}
+
+// Tiers are ordered from the smallest to the largest. The default tier keeps the values of the default config.
+var Tiers = []Tier{
+	{
+		Name: TierDefault,
+	},
+	{
+		Name:          TierLarge,
+		MinNodes:      250,
+		MinNamespaces: 2000,
+		ExtendedArguments: map[string]string{
+			"kube-api-qps":                          "300",
+			"kube-api-burst":                        "600",
+			"concurrent-deployment-syncs":           "10",
+			"concurrent-replicaset-syncs":           "10",
+			"concurrent-statefulset-syncs":          "10",
+			"concurrent-gc-syncs":                   "40",
+			"concurrent-namespace-syncs":            "20",
+			"concurrent-serviceaccount-token-syncs": "10",
+		},
+	},
+	{
+		Name:          TierXLarge,
+		MinNodes:      1000,
+		MinNamespaces: 10000,
+		ExtendedArguments: map[string]string{
+			"kube-api-qps":                          "500",
+			"kube-api-burst":                        "1000",
+			"concurrent-deployment-syncs":           "20",
+			"concurrent-replicaset-syncs":           "20",
+			"concurrent-statefulset-syncs":          "20",
+			"concurrent-gc-syncs":                   "80",
+			"concurrent-namespace-syncs":            "40",
+			"concurrent-serviceaccount-token-syncs": "20",
+		},
+	},
+}
+
+// ObserveClusterSizing selects a sizing tier from the control plane topology, the number of nodes and the number of
+// namespaces and fills in the extended arguments of the tier.
+func ObserveClusterSizing(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (map[string]interface{}, []error) {
+	listers := genericListers.(configobservation.Listers)
+	errs := []error{}
+
+	currentTier, _, err := unstructured.NestedString(existingConfig, TierPath...)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	previouslyObservedConfig := map[string]interface{}{}
+	if len(currentTier) > 0 {
+		if tier, ok := tierByName(currentTier); ok {
+			previouslyObservedConfig, err = tierConfig(tier)
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	topology := configv1.HighlyAvailableTopologyMode
+	infrastructure, err := listers.InfrastructureLister().Get("cluster")
+	switch {
+	case errors.IsNotFound(err):
+		recorder.Warningf("ObserveClusterSizing", "Required infrastructures.%s/cluster not found", configv1.GroupName)
+	case err != nil:
+		return previouslyObservedConfig, append(errs, err)
+	default:
+		topology = infrastructure.Status.ControlPlaneTopology
+	}
+	nodes, err := listers.KubeNodeLister().List(labels.Everything())
+	if err != nil {
+		return previouslyObservedConfig, append(errs, err)
+	}
+	namespaces, err := listers.NamespaceLister().List(labels.Everything())
+	if err != nil {
+		return previouslyObservedConfig, append(errs, err)
+	}
+
+	tier := SelectTier(currentTier, topology, len(nodes), len(namespaces))
+	observedConfig, err := tierConfig(tier)
+	if err != nil {
+		return previouslyObservedConfig, append(errs, err)
+	}
+
+	if !equality.Semantic.DeepEqual(previouslyObservedConfig, observedConfig) {
+		recorder.Eventf("ObserveClusterSizing", "sizing tier changed from %q to %q for %d nodes and %d namespaces", currentTier, tier.Name, len(nodes), len(namespaces))
+	}
+	return observedConfig, errs
+}
+
+// SelectTier returns the largest tier whose thresholds are reached. The current tier is kept until the cluster shrinks
+// below scaleDownMargin of its thresholds.
+func SelectTier(currentTier string, topology configv1.TopologyMode, nodes, namespaces int) Tier {
+	if topology == configv1.SingleReplicaTopologyMode {
+		return SingleReplicaTier
+	}
+
+	selected := Tiers[0]
+	for _, tier := range Tiers {
+		if reaches(tier, nodes, namespaces, 1) {
+			selected = tier
+		}
+	}
+	for i, tier := range Tiers {
+		if tier.Name != currentTier {
+			continue
+		}
+		// a smaller tier is only selected once the cluster is well below the thresholds of the current one
+		if tierIndex(selected.Name) < i && reaches(tier, nodes, namespaces, scaleDownMargin) {
+			return tier
+		}
+	}
+	return selected
+}
+
+func reaches(tier Tier, nodes, namespaces int, margin float64) bool {
+	if tier.MinNodes == 0 && tier.MinNamespaces == 0 {
+		return true
+	}
+	return (tier.MinNodes > 0 && nodes >= int(math.Ceil(float64(tier.MinNodes)*margin))) ||
+		(tier.MinNamespaces > 0 && namespaces >= int(math.Ceil(float64(tier.MinNamespaces)*margin)))
+}
+
+func tierIndex(name string) int {
+	for i, tier := range Tiers {
+		if tier.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func tierByName(name string) (Tier, bool) {
+	if name == SingleReplicaTier.Name {
+		return SingleReplicaTier, true
+	}
+	if i := tierIndex(name); i >= 0 {
+		return Tiers[i], true
+	}
+	return Tier{}, false
+}
+
+func tierConfig(tier Tier) (map[string]interface{}, error) {
+	config := map[string]interface{}{}
+	if err := unstructured.SetNestedField(config, tier.Name, TierPath...); err != nil {
+		return nil, err
+	}
+	for name, value := range tier.ExtendedArguments {
+		if err := unstructured.SetNestedStringSlice(config, []string{value}, "extendedArguments", name); err != nil {
+			return nil, fmt.Errorf("failed to set %s of the %s tier: %w", name, tier.Name, err)
+		}
+	}
+	return config, nil
+}
@@ -0,0 +1,103 @@
+package sizing
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/metadata/metadatalister"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/clock"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/configobservation"
+)
+
+func TestSelectTier(t *testing.T) {
+	tests := []struct {
+		name        string
+		currentTier string
+		topology    configv1.TopologyMode
+		nodes       int
+		namespaces  int
+		expected    string
+	}{
+		{name: "small cluster", topology: configv1.HighlyAvailableTopologyMode, nodes: 6, namespaces: 80, expected: TierDefault},
+		{name: "single node", topology: configv1.SingleReplicaTopologyMode, nodes: 1, namespaces: 5000, expected: TierSingleReplica},
+		{name: "many nodes", topology: configv1.HighlyAvailableTopologyMode, nodes: 300, namespaces: 80, expected: TierLarge},
+		{name: "many namespaces", topology: configv1.HighlyAvailableTopologyMode, nodes: 6, namespaces: 12000, expected: TierXLarge},
+		{name: "scale up", currentTier: TierLarge, topology: configv1.HighlyAvailableTopologyMode, nodes: 1000, expected: TierXLarge},
+		{name: "slightly below the current tier", currentTier: TierLarge, topology: configv1.HighlyAvailableTopologyMode, nodes: 240, expected: TierLarge},
+		{name: "well below the current tier", currentTier: TierLarge, topology: configv1.HighlyAvailableTopologyMode, nodes: 199, expected: TierDefault},
+		{name: "below the largest tier", currentTier: TierXLarge, topology: configv1.HighlyAvailableTopologyMode, nodes: 700, expected: TierLarge},
+		{name: "unknown current tier", currentTier: "Foo", topology: configv1.HighlyAvailableTopologyMode, nodes: 240, expected: TierDefault},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := SelectTier(test.currentTier, test.topology, test.nodes, test.namespaces); actual.Name != test.expected {
+				t.Errorf("expected tier %s, got %s", test.expected, actual.Name)
+			}
+		})
+	}
+}
+
+func TestObserveClusterSizing(t *testing.T) {
+	infraIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := infraIndexer.Add(&configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Status:     configv1.InfrastructureStatus{ControlPlaneTopology: configv1.HighlyAvailableTopologyMode},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	nodeIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for i := 0; i < 300; i++ {
+		if err := nodeIndexer.Add(&metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("node-%d", i)}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	listers := configobservation.Listers{
+		InfrastructureLister_: configlistersv1.NewInfrastructureLister(infraIndexer),
+		KubeNodeLister_:       metadatalister.New(nodeIndexer, corev1.SchemeGroupVersion.WithResource("nodes")),
+		NamespaceLister_:      metadatalister.New(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{}), corev1.SchemeGroupVersion.WithResource("namespaces")),
+	}
+	recorder := events.NewInMemoryRecorder("sizing", clock.RealClock{})
+
+	result, errs := ObserveClusterSizing(listers, recorder, map[string]interface{}{})
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	expected := map[string]interface{}{
+		"targetconfigcontroller": map[string]interface{}{
+			"sizing": map[string]interface{}{"tier": TierLarge},
+		},
+		"extendedArguments": map[string]interface{}{
+			"kube-api-qps":                          []interface{}{"300"},
+			"kube-api-burst":                        []interface{}{"600"},
+			"concurrent-deployment-syncs":           []interface{}{"10"},
+			"concurrent-replicaset-syncs":           []interface{}{"10"},
+			"concurrent-statefulset-syncs":          []interface{}{"10"},
+			"concurrent-gc-syncs":                   []interface{}{"40"},
+			"concurrent-namespace-syncs":            []interface{}{"20"},
+			"concurrent-serviceaccount-token-syncs": []interface{}{"10"},
+		},
+	}
+	if !reflect.DeepEqual(expected, result) {
+		t.Errorf("expected %#v, got %#v", expected, result)
+	}
+	if len(recorder.Events()) != 1 {
+		t.Errorf("expected a single tier change event, got %v", recorder.Events())
+	}
+
+	// observing the same cluster again does not change anything
+	if _, errs := ObserveClusterSizing(listers, recorder, result); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if len(recorder.Events()) != 1 {
+		t.Errorf("expected no further events, got %v", recorder.Events())
+	}
+}
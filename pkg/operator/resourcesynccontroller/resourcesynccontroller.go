@@ -1,10 +1,18 @@
 package resourcesynccontroller
 
 import (
+	"context"
+	"crypto/x509"
+	"fmt"
 	"time"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/util/cert"
+	"k8s.io/klog/v2"
 
+	operatorv1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/library-go/pkg/operator/events"
 	"github.com/openshift/library-go/pkg/operator/resourcesynccontroller"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
@@ -12,33 +20,153 @@ import (
 	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/operatorclient"
 )
 
-func AddSyncCSRControllerCA(resourceSyncController *resourcesynccontroller.ResourceSyncController) error {
+// AddSyncCSRControllerCA publishes the csr-controller-ca bundle once it trusts the csr-signer the kube-controller-manager
+// signs with, otherwise the kube-apiserver would reject client certificates issued by a new csr-signer. It is only
+// published while isCSRSignerLeader is true.
+func AddSyncCSRControllerCA(resourceSyncController *resourcesynccontroller.ResourceSyncController, operatorClient v1helpers.OperatorClient, kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces, isCSRSignerLeader func() bool) error {
+	configMapLister := kubeInformersForNamespaces.ConfigMapLister()
+	secretLister := kubeInformersForNamespaces.SecretLister()
 	return resourceSyncController.SyncConfigMapConditionally(
 		resourcesynccontroller.ResourceLocation{Namespace: operatorclient.GlobalMachineSpecifiedConfigNamespace, Name: "csr-controller-ca"},
 		resourcesynccontroller.ResourceLocation{Namespace: operatorclient.OperatorNamespace, Name: "csr-controller-ca"},
-		func() (bool, error) {
-			time.Sleep(6 * time.Second)
-			return true, nil
-		},
+		syncPreconditions(operatorClient, isCSRSignerLeader, "CSRControllerCASyncPending", "WaitingForCSRSigner", func() (string, error) {
+			return csrControllerCAPending(configMapLister, secretLister)
+		}),
 	)
 }
 
-func AddSyncClientCertKeySecret(resourceSyncController *resourcesynccontroller.ResourceSyncController) error {
+// AddSyncClientCertKeySecret copies the client certificate of the kube-controller-manager once it is valid and trusted
+// by its client-ca, otherwise the kube-controller-manager would be locked out of the kube-apiserver. It is only copied
+// while isCSRSignerLeader is true.
+func AddSyncClientCertKeySecret(resourceSyncController *resourcesynccontroller.ResourceSyncController, operatorClient v1helpers.OperatorClient, kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces, isCSRSignerLeader func() bool) error {
+	configMapLister := kubeInformersForNamespaces.ConfigMapLister()
+	secretLister := kubeInformersForNamespaces.SecretLister()
 	return resourceSyncController.SyncSecretConditionally(
 		resourcesynccontroller.ResourceLocation{Namespace: operatorclient.TargetNamespace, Name: "kube-controller-manager-client-cert-key"},
 		resourcesynccontroller.ResourceLocation{Namespace: operatorclient.GlobalMachineSpecifiedConfigNamespace, Name: "kube-controller-manager-client-cert-key"},
-		func() (bool, error) {
-			time.Sleep(6 * time.Second)
-			return true, nil
-		},
+		syncPreconditions(operatorClient, isCSRSignerLeader, "ClientCertKeySyncPending", "WaitingForTrustedClientCert", func() (string, error) {
+			return clientCertKeyPending(configMapLister, secretLister, time.Now())
+		}),
 	)
 }
 
+// syncPreconditions returns the preconditions of a sync rule. The rule is held back while pending returns a message,
+// which is reported in the condition of the operator status. Without the csr-signer lease the rule is held back and the
+// condition is left to the holder.
+func syncPreconditions(operatorClient v1helpers.OperatorClient, isCSRSignerLeader func() bool, conditionType, reason string, pending func() (string, error)) func() (bool, error) {
+	return func() (bool, error) {
+		if !isCSRSignerLeader() {
+			return false, nil
+		}
+		message, err := pending()
+		if err != nil {
+			return false, err
+		}
+		condition := operatorv1.OperatorCondition{
+			Type:   conditionType,
+			Status: operatorv1.ConditionFalse,
+			Reason: "AsExpected",
+		}
+		if len(message) > 0 {
+			klog.V(2).Infof("Holding back the sync: %s", message)
+			condition.Status = operatorv1.ConditionTrue
+			condition.Reason = reason
+			condition.Message = message
+		}
+		if _, _, err := v1helpers.UpdateStatus(context.TODO(), operatorClient, v1helpers.UpdateConditionFn(condition)); err != nil {
+			return false, err
+		}
+		return len(message) == 0, nil
+	}
+}
+
+// csrControllerCAPending returns why the csr-controller-ca must not be published yet, nothing once it contains the
+// csr-signer of the kube-controller-manager or when there is no csr-signer yet.
+func csrControllerCAPending(configMapLister corev1listers.ConfigMapLister, secretLister corev1listers.SecretLister) (string, error) {
+	csrSigner, err := secretLister.Secrets(operatorclient.TargetNamespace).Get("csr-signer")
+	if apierrors.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if len(csrSigner.Data["tls.crt"]) == 0 {
+		return "", nil
+	}
+	signerCerts, err := cert.ParseCertsPEM(csrSigner.Data["tls.crt"])
+	if err != nil {
+		return "", fmt.Errorf("%s/csr-signer is malformed: %w", operatorclient.TargetNamespace, err)
+	}
+
+	caBundle, err := configMapLister.ConfigMaps(operatorclient.OperatorNamespace).Get("csr-controller-ca")
+	if apierrors.IsNotFound(err) {
+		return fmt.Sprintf("%s/csr-controller-ca does not exist", operatorclient.OperatorNamespace), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	bundleCerts, err := cert.ParseCertsPEM([]byte(caBundle.Data["ca-bundle.crt"]))
+	if err != nil {
+		return fmt.Sprintf("%s/csr-controller-ca is malformed: %v", operatorclient.OperatorNamespace, err), nil
+	}
+	for _, bundleCert := range bundleCerts {
+		if bundleCert.Equal(signerCerts[0]) {
+			return "", nil
+		}
+	}
+	return fmt.Sprintf("%s/csr-controller-ca does not contain the csr-signer %q yet", operatorclient.OperatorNamespace, signerCerts[0].Subject.CommonName), nil
+}
+
+// clientCertKeyPending returns why the client certificate must not be copied yet, nothing once it is valid and signed
+// by a CA of the client-ca of the kube-controller-manager.
+func clientCertKeyPending(configMapLister corev1listers.ConfigMapLister, secretLister corev1listers.SecretLister, now time.Time) (string, error) {
+	clientCertKey, err := secretLister.Secrets(operatorclient.GlobalMachineSpecifiedConfigNamespace).Get("kube-controller-manager-client-cert-key")
+	if apierrors.IsNotFound(err) {
+		return fmt.Sprintf("%s/kube-controller-manager-client-cert-key does not exist", operatorclient.GlobalMachineSpecifiedConfigNamespace), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	certs, err := cert.ParseCertsPEM(clientCertKey.Data["tls.crt"])
+	if err != nil {
+		return fmt.Sprintf("%s/kube-controller-manager-client-cert-key is malformed: %v", operatorclient.GlobalMachineSpecifiedConfigNamespace, err), nil
+	}
+	if now.Before(certs[0].NotBefore) || now.After(certs[0].NotAfter) {
+		return fmt.Sprintf("%s/kube-controller-manager-client-cert-key is valid from %s to %s", operatorclient.GlobalMachineSpecifiedConfigNamespace, certs[0].NotBefore.Format(time.RFC3339), certs[0].NotAfter.Format(time.RFC3339)), nil
+	}
+
+	clientCA, err := configMapLister.ConfigMaps(operatorclient.TargetNamespace).Get("client-ca")
+	if apierrors.IsNotFound(err) {
+		return fmt.Sprintf("%s/client-ca does not exist", operatorclient.TargetNamespace), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	roots, err := cert.NewPoolFromBytes([]byte(clientCA.Data["ca-bundle.crt"]))
+	if err != nil {
+		return fmt.Sprintf("%s/client-ca is malformed: %v", operatorclient.TargetNamespace, err), nil
+	}
+	intermediates := x509.NewCertPool()
+	for _, intermediate := range certs[1:] {
+		intermediates.AddCert(intermediate)
+	}
+	if _, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}); err != nil {
+		return fmt.Sprintf("%s/kube-controller-manager-client-cert-key is not trusted by %s/client-ca yet: %v", operatorclient.GlobalMachineSpecifiedConfigNamespace, operatorclient.TargetNamespace, err), nil
+	}
+	return "", nil
+}
+
 func NewResourceSyncController(
 	operatorConfigClient v1helpers.OperatorClient,
 	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
 	secretsGetter corev1client.SecretsGetter,
 	configMapsGetter corev1client.ConfigMapsGetter,
+	isCSRSignerLeader func() bool,
 	eventRecorder events.Recorder) (*resourcesynccontroller.ResourceSyncController, error) {
 
 	resourceSyncController := resourcesynccontroller.NewResourceSyncController(
@@ -49,10 +177,10 @@ func NewResourceSyncController(
 		v1helpers.CachedConfigMapGetter(configMapsGetter, kubeInformersForNamespaces),
 		eventRecorder,
 	)
-	if err := AddSyncCSRControllerCA(resourceSyncController); err != nil {
+	if err := AddSyncCSRControllerCA(resourceSyncController, operatorConfigClient, kubeInformersForNamespaces, isCSRSignerLeader); err != nil {
 		return nil, err
 	}
-	if err := AddSyncClientCertKeySecret(resourceSyncController); err != nil {
+	if err := AddSyncClientCertKeySecret(resourceSyncController, operatorConfigClient, kubeInformersForNamespaces, isCSRSignerLeader); err != nil {
 		return nil, err
 	}
 	if err := resourceSyncController.SyncConfigMap(
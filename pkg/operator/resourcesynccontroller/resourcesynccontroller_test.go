@@ -0,0 +1,223 @@
+package resourcesynccontroller
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/operatorclient"
+)
+
+func newCA(t *testing.T, name string) *crypto.CA {
+	t.Helper()
+	config, err := crypto.MakeSelfSignedCAConfigForDuration(name, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &crypto.CA{Config: config, SerialGenerator: &crypto.RandomSerialGenerator{}}
+}
+
+func pemBytes(t *testing.T, config *crypto.TLSCertificateConfig) []byte {
+	t.Helper()
+	certBytes, _, err := config.GetPEMBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return certBytes
+}
+
+func newListers(t *testing.T, objects ...interface{}) (corev1listers.ConfigMapLister, corev1listers.SecretLister) {
+	t.Helper()
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, obj := range objects {
+		if err := indexer.Add(obj); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return corev1listers.NewConfigMapLister(indexer), corev1listers.NewSecretLister(indexer)
+}
+
+func newSecret(namespace, name string, certBytes []byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Data:       map[string][]byte{"tls.crt": certBytes},
+	}
+}
+
+func newBundle(namespace, name string, bundles ...[]byte) *corev1.ConfigMap {
+	content := ""
+	for _, bundle := range bundles {
+		content += string(bundle)
+	}
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Data:       map[string]string{"ca-bundle.crt": content},
+	}
+}
+
+func TestCSRControllerCAPending(t *testing.T) {
+	csrSigner := pemBytes(t, newCA(t, "kube-csr-signer@2").Config)
+	previousCSRSigner := pemBytes(t, newCA(t, "kube-csr-signer@1").Config)
+
+	tests := []struct {
+		name            string
+		objects         []interface{}
+		expectedPending string
+	}{
+		{
+			name:    "no csr-signer yet",
+			objects: []interface{}{newBundle(operatorclient.OperatorNamespace, "csr-controller-ca", previousCSRSigner)},
+		},
+		{
+			name: "bundle contains the csr-signer",
+			objects: []interface{}{
+				newSecret(operatorclient.TargetNamespace, "csr-signer", csrSigner),
+				newBundle(operatorclient.OperatorNamespace, "csr-controller-ca", previousCSRSigner, csrSigner),
+			},
+		},
+		{
+			name: "bundle misses the csr-signer",
+			objects: []interface{}{
+				newSecret(operatorclient.TargetNamespace, "csr-signer", csrSigner),
+				newBundle(operatorclient.OperatorNamespace, "csr-controller-ca", previousCSRSigner),
+			},
+			expectedPending: `does not contain the csr-signer "kube-csr-signer@2"`,
+		},
+		{
+			name:            "no bundle",
+			objects:         []interface{}{newSecret(operatorclient.TargetNamespace, "csr-signer", csrSigner)},
+			expectedPending: "csr-controller-ca does not exist",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			configMapLister, secretLister := newListers(t, test.objects...)
+			pending, err := csrControllerCAPending(configMapLister, secretLister)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(pending, test.expectedPending) || (len(test.expectedPending) == 0 && len(pending) > 0) {
+				t.Errorf("expected %q, got %q", test.expectedPending, pending)
+			}
+		})
+	}
+}
+
+func TestClientCertKeyPending(t *testing.T) {
+	signer := newCA(t, "kube-control-plane-signer")
+	otherSigner := newCA(t, "other-signer")
+	clientCert, err := signer.MakeClientCertificateForDuration(&user.DefaultInfo{Name: "system:kube-controller-manager"}, 30*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCertKey := newSecret(operatorclient.GlobalMachineSpecifiedConfigNamespace, "kube-controller-manager-client-cert-key", pemBytes(t, clientCert))
+
+	tests := []struct {
+		name            string
+		objects         []interface{}
+		now             time.Time
+		expectedPending string
+	}{
+		{
+			name: "trusted by the client-ca",
+			objects: []interface{}{
+				clientCertKey,
+				newBundle(operatorclient.TargetNamespace, "client-ca", pemBytes(t, otherSigner.Config), pemBytes(t, signer.Config)),
+			},
+			now: time.Now(),
+		},
+		{
+			name: "not trusted by the client-ca",
+			objects: []interface{}{
+				clientCertKey,
+				newBundle(operatorclient.TargetNamespace, "client-ca", pemBytes(t, otherSigner.Config)),
+			},
+			now:             time.Now(),
+			expectedPending: "is not trusted by openshift-kube-controller-manager/client-ca yet",
+		},
+		{
+			name: "expired",
+			objects: []interface{}{
+				clientCertKey,
+				newBundle(operatorclient.TargetNamespace, "client-ca", pemBytes(t, signer.Config)),
+			},
+			now:             time.Now().Add(45 * time.Minute),
+			expectedPending: "kube-controller-manager-client-cert-key is valid from",
+		},
+		{
+			name:            "no client-ca",
+			objects:         []interface{}{clientCertKey},
+			now:             time.Now(),
+			expectedPending: "client-ca does not exist",
+		},
+		{
+			name:            "no client cert",
+			now:             time.Now(),
+			expectedPending: "kube-controller-manager-client-cert-key does not exist",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			configMapLister, secretLister := newListers(t, test.objects...)
+			pending, err := clientCertKeyPending(configMapLister, secretLister, test.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(pending, test.expectedPending) || (len(test.expectedPending) == 0 && len(pending) > 0) {
+				t.Errorf("expected %q, got %q", test.expectedPending, pending)
+			}
+		})
+	}
+}
+
+func TestSyncPreconditions(t *testing.T) {
+	operatorClient := v1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{}, &operatorv1.OperatorStatus{}, nil)
+	message := "waiting"
+	leader := false
+	preconditions := syncPreconditions(operatorClient, func() bool { return leader }, "TestSyncPending", "Waiting", func() (string, error) { return message, nil })
+
+	// without the lease the rule is held back and the condition is left to the holder
+	if fulfilled, err := preconditions(); fulfilled || err != nil {
+		t.Fatalf("expected the rule to be held back without the lease, got %v %v", fulfilled, err)
+	}
+	if _, status, _, _ := operatorClient.GetOperatorState(); v1helpers.FindOperatorCondition(status.Conditions, "TestSyncPending") != nil {
+		t.Fatalf("expected no condition without the lease")
+	}
+
+	leader = true
+	for _, expected := range []struct {
+		fulfilled bool
+		status    operatorv1.ConditionStatus
+		reason    string
+	}{
+		{fulfilled: false, status: operatorv1.ConditionTrue, reason: "Waiting"},
+		{fulfilled: true, status: operatorv1.ConditionFalse, reason: "AsExpected"},
+	} {
+		fulfilled, err := preconditions()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fulfilled != expected.fulfilled {
+			t.Errorf("expected fulfilled %v, got %v", expected.fulfilled, fulfilled)
+		}
+		_, status, _, err := operatorClient.GetOperatorState()
+		if err != nil {
+			t.Fatal(err)
+		}
+		condition := v1helpers.FindOperatorCondition(status.Conditions, "TestSyncPending")
+		if condition == nil || condition.Status != expected.status || condition.Reason != expected.reason || condition.Message != message {
+			t.Errorf("expected %s %s, got %#v", expected.status, expected.reason, condition)
+		}
+		message = ""
+	}
+}
@@ -13,9 +13,12 @@ import (
 	configinformers "github.com/openshift/client-go/config/informers/externalversions"
 	configinformersv1 "github.com/openshift/client-go/config/informers/externalversions/config/v1"
 	applyoperatorv1 "github.com/openshift/client-go/operator/applyconfigurations/operator/v1"
+	operatorv1client "github.com/openshift/client-go/operator/clientset/versioned/typed/operator/v1"
 	"github.com/openshift/cluster-kube-controller-manager-operator/bindata"
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/certmetrics"
 	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/certrotationcontroller"
 	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/configobservation/configobservercontroller"
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/configobservation/csrsigning"
 	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/configobservation/node"
 	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/gcwatchercontroller"
 	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/operatorclient"
@@ -42,6 +45,8 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/metadata/metadatainformer"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/ptr"
 )
@@ -58,6 +63,11 @@ func RunOperator(ctx context.Context, cc *controllercmd.ControllerContext) error
 	}
 	clusterInformers := v1helpers.NewKubeInformersForNamespaces(kubeClient, "")
 	configInformers := configinformers.NewSharedInformerFactory(configClient, 10*time.Minute)
+	metadataClient, err := metadata.NewForConfig(cc.KubeConfig)
+	if err != nil {
+		return err
+	}
+	metadataInformers := metadatainformer.NewSharedInformerFactory(metadataClient, 10*time.Minute)
 	kubeInformersForNamespaces := v1helpers.NewKubeInformersForNamespaces(kubeClient,
 		"",
 		operatorclient.GlobalUserSpecifiedConfigNamespace,
@@ -102,11 +112,17 @@ func RunOperator(ctx context.Context, cc *controllercmd.ControllerContext) error
 		return fmt.Errorf("timed out waiting for FeatureGate detection")
 	}
 
+	// the target config controller and the resource sync resync every minute, it picks up the CSR signer once the lease is acquired
+	csrSignerLease, err := targetconfigcontroller.NewCSRSignerLease(kubeClient, "kube-controller-manager-operator", nil)
+	if err != nil {
+		return err
+	}
 	resourceSyncController, err := resourcesynccontroller.NewResourceSyncController(
 		operatorClient,
 		kubeInformersForNamespaces,
 		v1helpers.CachedSecretGetter(kubeClient.CoreV1(), kubeInformersForNamespaces),
 		v1helpers.CachedConfigMapGetter(kubeClient.CoreV1(), kubeInformersForNamespaces),
+		csrSignerLease.IsLeader,
 		cc.EventRecorder,
 	)
 	if err != nil {
@@ -117,6 +133,7 @@ func RunOperator(ctx context.Context, cc *controllercmd.ControllerContext) error
 		operatorClient,
 		configInformers,
 		kubeInformersForNamespaces,
+		metadataInformers,
 		resourceSyncController,
 		featureGateAccessor,
 		cc.EventRecorder,
@@ -190,9 +207,16 @@ func RunOperator(ctx context.Context, cc *controllercmd.ControllerContext) error
 		operatorLister,
 		kubeClient,
 		configInformers.Config().V1().Infrastructures(),
+		csrSignerLease,
 		cc.EventRecorder,
 	)
 
+	featureGates, err := featureGateAccessor.CurrentFeatureGates()
+	if err != nil {
+		return err
+	}
+	certmetrics.Register(kubeInformersForNamespaces.SecretLister(), kubeInformersForNamespaces.ConfigMapLister(), csrsigning.DefaultSignerValidity(featureGates))
+
 	// don't change any versions until we sync
 	versionRecorder := status.NewVersionGetter()
 	clusterOperator, err := configClient.ConfigV1().ClusterOperators().Get(ctx, "kube-controller-manager", metav1.GetOptions{})
@@ -209,6 +233,7 @@ func RunOperator(ctx context.Context, cc *controllercmd.ControllerContext) error
 		WithInstaller([]string{"cluster-kube-controller-manager-operator", "installer"}).
 		WithPruning([]string{"cluster-kube-controller-manager-operator", "prune"}, "kube-controller-manager-pod").
 		WithRevisionedResources(operatorclient.TargetNamespace, "kube-controller-manager", deploymentConfigMaps, deploymentSecrets).
+		WithRevisionControllerPrecondition(targetconfigcontroller.NewRevisionPreviewPrecondition(operatorLister)).
 		WithUnrevisionedCerts("kube-controller-manager-certs", CertConfigMaps, CertSecrets).
 		WithVersioning("kube-controller-manager", versionRecorder).
 		WithPodDisruptionBudgetGuard(
@@ -265,7 +290,11 @@ func RunOperator(ctx context.Context, cc *controllercmd.ControllerContext) error
 	if err != nil {
 		return err
 	}
-	saTokenController := certrotationcontroller.NewSATokenSignerController(operatorClient, kubeInformersForNamespaces, kubeClient, cc.EventRecorder)
+	operatorConfigClient, err := operatorv1client.NewForConfig(cc.KubeConfig)
+	if err != nil {
+		return err
+	}
+	saTokenController := certrotationcontroller.NewSATokenSignerController(operatorClient, kubeInformersForNamespaces, kubeClient, operatorConfigClient, cc.EventRecorder)
 
 	latencyProfileRejectionChecker, err := latencyprofilecontroller.NewInstallerProfileRejectionChecker(
 		kubeInformersForNamespaces.ConfigMapLister().ConfigMaps(operatorclient.TargetNamespace),
@@ -296,10 +325,12 @@ func RunOperator(ctx context.Context, cc *controllercmd.ControllerContext) error
 	configInformers.Start(ctx.Done())
 	clusterInformers.Start(ctx.Done())
 	kubeInformersForNamespaces.Start(ctx.Done())
+	metadataInformers.Start(ctx.Done())
 	dynamicInformers.Start(ctx.Done())
 
 	go staticPodControllers.Start(ctx)
 	go staticResourceController.Run(ctx, 1)
+	go csrSignerLease.Run(ctx)
 	go targetConfigController.Run(ctx, 1)
 	go configObserver.Run(ctx, 1)
 	go clusterOperatorStatus.Run(ctx, 1)
@@ -0,0 +1,337 @@
+package targetconfigcontroller
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/bindata"
+)
+
+// kubeControllerManagerExec is the command in the kube-controller-manager container script that all flags are passed to.
+const kubeControllerManagerExec = "exec hyperkube kube-controller-manager"
+
+// ArgumentSource names the layer that contributed a kube-controller-manager flag.
+type ArgumentSource string
+
+const (
+	// ArgumentSourcePodManifest is used for flags hardcoded in assets/kube-controller-manager/pod.yaml.
+	ArgumentSourcePodManifest ArgumentSource = "pod.yaml"
+	// ArgumentSourceConfig is used for extendedArguments of the merged kube-controller-manager config.
+	ArgumentSourceConfig ArgumentSource = "extendedArguments"
+	// ArgumentSourceDefaultConfig is used for extendedArguments of assets/config/defaultconfig.yaml. Any other source
+	// replaces them.
+	ArgumentSourceDefaultConfig ArgumentSource = "defaultconfig.yaml"
+	// ArgumentSourceObservedConfig is used for extendedArguments of the observedConfig.
+	ArgumentSourceObservedConfig ArgumentSource = "observedConfig"
+	// ArgumentSourceUnsupportedConfigOverrides is used for extendedArguments of the unsupportedConfigOverrides.
+	ArgumentSourceUnsupportedConfigOverrides ArgumentSource = "unsupportedConfigOverrides"
+	// ArgumentSourceServingCert is used for flags pointing at the serving-cert secret.
+	ArgumentSourceServingCert ArgumentSource = "serving-cert"
+	// ArgumentSourceServingInfo is used for flags derived from the observed servingInfo.
+	ArgumentSourceServingInfo ArgumentSource = "servingInfo"
+	// ArgumentSourceLogLevel is used for flags derived from the operator log level.
+	ArgumentSourceLogLevel ArgumentSource = "logLevel"
+	// ArgumentSourceLogging is used for flags set by the per-container logging config.
+	ArgumentSourceLogging ArgumentSource = "targetconfigcontroller.logging"
+)
+
+// renderGroups orders the flags that are not hardcoded in the pod manifest. They are appended to the manifest flags in
+// the order the kube-controller-manager command line always had, so that existing pods are rendered unchanged.
+var renderGroups = map[ArgumentSource]int{
+	ArgumentSourceLogLevel:                   1,
+	ArgumentSourceLogging:                    1,
+	ArgumentSourceServingCert:                2,
+	ArgumentSourceConfig:                     3,
+	ArgumentSourceDefaultConfig:              3,
+	ArgumentSourceObservedConfig:             3,
+	ArgumentSourceUnsupportedConfigOverrides: 3,
+	ArgumentSourceServingInfo:                4,
+}
+
+// Argument is a single --name=value flag of the kube-controller-manager.
+type Argument struct {
+	Name   string
+	Value  string
+	Source ArgumentSource
+}
+
+// String renders the flag. Single letter flags like klog's -v keep their single dash.
+func (a Argument) String() string {
+	if len(a.Name) == 1 {
+		return fmt.Sprintf("-%s=%s", a.Name, a.Value)
+	}
+	return fmt.Sprintf("--%s=%s", a.Name, a.Value)
+}
+
+// Arguments is an ordered collection of kube-controller-manager flags.
+// A flag is owned by the first source that sets it. The owner may set a flag multiple times (e.g. --controllers),
+// any other source setting the same flag to a different value is a conflict. Flags of the default config are the
+// exception, any other source replaces them.
+type Arguments struct {
+	// fixed holds the names of the flags that keep their insertion order when rendered.
+	fixed []string
+	args  map[string][]Argument
+}
+
+func NewArguments() *Arguments {
+	return &Arguments{args: map[string][]Argument{}}
+}
+
+// Add sets the flag name to the given values on behalf of source. Values already set for the flag are deduplicated.
+func (a *Arguments) Add(source ArgumentSource, name string, values ...string) error {
+	if err := validateArgument(name, values...); err != nil {
+		return fmt.Errorf("%s: %w", source, err)
+	}
+
+	existing, found := a.args[name]
+	switch {
+	case found && existing[0].Source != source && source == ArgumentSourceDefaultConfig:
+		return nil
+	case found && existing[0].Source != source && existing[0].Source == ArgumentSourceDefaultConfig:
+		existing, found = nil, false
+	case found && existing[0].Source != source:
+		if !sameValues(existing, values) {
+			return &ArgumentConflictError{Name: name, Existing: existing, Source: source, Values: values}
+		}
+		return nil
+	}
+	if !found && source == ArgumentSourcePodManifest {
+		a.fixed = append(a.fixed, name)
+	}
+	for _, value := range values {
+		if hasValue(existing, value) {
+			continue
+		}
+		existing = append(existing, Argument{Name: name, Value: value, Source: source})
+	}
+	a.args[name] = existing
+	return nil
+}
+
+// Get returns the arguments set for the flag name.
+func (a *Arguments) Get(name string) []Argument {
+	return a.args[name]
+}
+
+// List returns all flags in render order. Flags from the pod manifest keep their order, all other flags follow grouped
+// by source as listed in renderGroups and sorted by their rendered string within a group, so that the result does not
+// depend on the order in which they were added.
+func (a *Arguments) List() []Argument {
+	ret := []Argument{}
+	fixed := map[string]bool{}
+	for _, name := range a.fixed {
+		fixed[name] = true
+		ret = append(ret, a.args[name]...)
+	}
+
+	rest := []Argument{}
+	for name, args := range a.args {
+		if fixed[name] {
+			continue
+		}
+		rest = append(rest, args...)
+	}
+	sort.Slice(rest, func(i, j int) bool {
+		if renderGroups[rest[i].Source] != renderGroups[rest[j].Source] {
+			return renderGroups[rest[i].Source] < renderGroups[rest[j].Source]
+		}
+		return rest[i].String() < rest[j].String()
+	})
+
+	return append(ret, rest...)
+}
+
+// Strings returns the flags in render order as --name=value strings.
+func (a *Arguments) Strings() []string {
+	ret := []string{}
+	for _, arg := range a.List() {
+		ret = append(ret, arg.String())
+	}
+	return ret
+}
+
+// ArgumentConflictError is returned when two sources set the same flag to different values.
+type ArgumentConflictError struct {
+	Name     string
+	Existing []Argument
+	Source   ArgumentSource
+	Values   []string
+}
+
+func (e *ArgumentConflictError) Error() string {
+	existingValues := []string{}
+	for _, arg := range e.Existing {
+		existingValues = append(existingValues, arg.Value)
+	}
+	return fmt.Sprintf("flag --%s set by %s to %q conflicts with %q set by %s",
+		e.Name, e.Source, strings.Join(e.Values, ","), strings.Join(existingValues, ","), e.Existing[0].Source)
+}
+
+// parseKubeControllerManagerCommand returns the flags the kube-controller-manager is invoked with in its container
+// script. The script is expected to end with the kube-controller-manager invocation.
+func parseKubeControllerManagerCommand(script string) (*Arguments, error) {
+	idx := strings.Index(script, kubeControllerManagerExec)
+	if idx < 0 {
+		return nil, fmt.Errorf("%s not found in first argument %q", kubeControllerManagerExec, script)
+	}
+
+	args := NewArguments()
+	flags := strings.ReplaceAll(script[idx+len(kubeControllerManagerExec):], "\\\n", " ")
+	for _, flag := range strings.Fields(flags) {
+		name, value, found := strings.Cut(strings.TrimPrefix(flag, "--"), "=")
+		if !strings.HasPrefix(flag, "--") || !found {
+			return nil, fmt.Errorf("unexpected kube-controller-manager argument %q, expected --name=value", flag)
+		}
+		if err := args.Add(ArgumentSourcePodManifest, name, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return args, nil
+}
+
+// renderKubeControllerManagerCommand appends the flags of args that are not hardcoded in the pod manifest to the
+// kube-controller-manager container script. The script itself is kept as is.
+func renderKubeControllerManagerCommand(script string, args *Arguments) string {
+	rendered := []string{script}
+	for _, arg := range args.List() {
+		if arg.Source != ArgumentSourcePodManifest {
+			rendered = append(rendered, arg.String())
+		}
+	}
+	return strings.Join(rendered, " ")
+}
+
+// addConfigExtendedArguments adds the extendedArguments of every layer of the kube-controller-manager config with the
+// source of that layer, so that the observedConfig and the unsupportedConfigOverrides cannot silently replace each
+// other's flags like they do when the layers are merged.
+func addConfigExtendedArguments(args *Arguments, operatorSpec *operatorv1.StaticPodOperatorSpec) error {
+	layers := []struct {
+		source ArgumentSource
+		config []byte
+	}{
+		{ArgumentSourceDefaultConfig, bindata.MustAsset("assets/config/defaultconfig.yaml")},
+		{ArgumentSourceObservedConfig, operatorSpec.ObservedConfig.Raw},
+		{ArgumentSourceUnsupportedConfigOverrides, operatorSpec.UnsupportedConfigOverrides.Raw},
+	}
+
+	errs := []error{}
+	for _, layer := range layers {
+		if len(layer.config) == 0 {
+			continue
+		}
+		var config map[string]interface{}
+		if err := yaml.Unmarshal(layer.config, &config); err != nil {
+			return fmt.Errorf("failed to unmarshal the %s: %w", layer.source, err)
+		}
+		if err := addExtendedArguments(args, layer.source, config); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// addExtendedArguments adds the extendedArguments of a kube-controller-manager config to args.
+func addExtendedArguments(args *Arguments, source ArgumentSource, config map[string]interface{}) error {
+	extendedArguments, ok := config["extendedArguments"]
+	if !ok || extendedArguments == nil {
+		return nil
+	}
+	extendedArgumentsMap, ok := extendedArguments.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("extendedArguments must be a map, got %T", extendedArguments)
+	}
+
+	errs := []error{}
+	for key, value := range extendedArgumentsMap {
+		if value == nil {
+			continue
+		}
+		arrayValue, ok := value.([]interface{})
+		if !ok {
+			errs = append(errs, fmt.Errorf("extendedArguments.%s must be a list of strings, got %T", key, value))
+			continue
+		}
+		values := []string{}
+		for _, item := range arrayValue {
+			stringValue, ok := item.(string)
+			if !ok {
+				errs = append(errs, fmt.Errorf("extendedArguments.%s must be a list of strings, got item %T", key, item))
+				continue
+			}
+			values = append(values, stringValue)
+		}
+		if len(values) == 0 {
+			continue
+		}
+		if err := args.Add(source, key, values...); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	// map iteration order is random, keep the message stable
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return utilerrors.NewAggregate(errs)
+}
+
+// flagName matches the names of kube-controller-manager flags.
+var flagName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// unsafeValueCharacters are not allowed in flag values. The kube-controller-manager flags are rendered into a bash
+// script unquoted, so whitespace, quotes and shell metacharacters would change the command. Glob characters are
+// allowed, --controllers needs them, and a glob starting with the flag name does not match any file in the container.
+const unsafeValueCharacters = " \t\n\"'`$;&|\\<>(){}"
+
+func validateArgument(name string, values ...string) error {
+	if len(name) == 0 {
+		return fmt.Errorf("empty flag name")
+	}
+	if !flagName.MatchString(name) {
+		return fmt.Errorf("invalid flag name %q", name)
+	}
+	if len(values) == 0 {
+		return fmt.Errorf("flag --%s has no value", name)
+	}
+	for _, value := range values {
+		if strings.ContainsAny(value, unsafeValueCharacters) {
+			return fmt.Errorf("flag --%s has invalid value %q: whitespace, quotes and shell metacharacters are not allowed", name, value)
+		}
+	}
+	return nil
+}
+
+func sameValues(existing []Argument, values []string) bool {
+	for _, value := range values {
+		if !hasValue(existing, value) {
+			return false
+		}
+	}
+	for _, arg := range existing {
+		found := false
+		for _, value := range values {
+			if arg.Value == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func hasValue(args []Argument, value string) bool {
+	for _, arg := range args {
+		if arg.Value == value {
+			return true
+		}
+	}
+	return false
+}
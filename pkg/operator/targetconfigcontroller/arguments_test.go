@@ -0,0 +1,243 @@
+package targetconfigcontroller
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/bindata"
+)
+
+func TestArgumentsAdd(t *testing.T) {
+	type add struct {
+		source ArgumentSource
+		name   string
+		values []string
+	}
+	tests := []struct {
+		name          string
+		adds          []add
+		expected      []string
+		expectedError string
+	}{
+		{
+			name: "pod manifest order is kept, the rest is grouped by source and sorted",
+			adds: []add{
+				{ArgumentSourcePodManifest, "openshift-config", []string{"/config.yaml"}},
+				{ArgumentSourcePodManifest, "kubeconfig", []string{"/kubeconfig"}},
+				{ArgumentSourceServingInfo, "tls-min-version", []string{"VersionTLS12"}},
+				{ArgumentSourceObservedConfig, "leader-elect", []string{"true"}},
+				{ArgumentSourceLogLevel, "v", []string{"2"}},
+				{ArgumentSourceConfig, "controllers", []string{"*", "-ttl"}},
+				{ArgumentSourceDefaultConfig, "leader-elect-retry-period", []string{"3s"}},
+				{ArgumentSourceServingCert, "tls-cert-file", []string{"/tls.crt"}},
+			},
+			expected: []string{
+				"--openshift-config=/config.yaml", "--kubeconfig=/kubeconfig", "-v=2", "--tls-cert-file=/tls.crt",
+				"--controllers=*", "--controllers=-ttl", "--leader-elect-retry-period=3s", "--leader-elect=true",
+				"--tls-min-version=VersionTLS12",
+			},
+		},
+		{
+			name: "duplicate values from the same source are dropped",
+			adds: []add{
+				{ArgumentSourceConfig, "controllers", []string{"*", "-ttl", "*"}},
+				{ArgumentSourceConfig, "controllers", []string{"-ttl"}},
+			},
+			expected: []string{"--controllers=*", "--controllers=-ttl"},
+		},
+		{
+			name: "same value from another source is deduplicated",
+			adds: []add{
+				{ArgumentSourceServingInfo, "tls-min-version", []string{"VersionTLS12"}},
+				{ArgumentSourceConfig, "tls-min-version", []string{"VersionTLS12"}},
+			},
+			expected: []string{"--tls-min-version=VersionTLS12"},
+		},
+		{
+			name: "different value from another source conflicts",
+			adds: []add{
+				{ArgumentSourceServingInfo, "tls-min-version", []string{"VersionTLS12"}},
+				{ArgumentSourceConfig, "tls-min-version", []string{"VersionTLS13"}},
+			},
+			expectedError: `flag --tls-min-version set by extendedArguments to "VersionTLS13" conflicts with "VersionTLS12" set by servingInfo`,
+		},
+		{
+			name: "default config is replaced by another source",
+			adds: []add{
+				{ArgumentSourceDefaultConfig, "cluster-signing-duration", []string{"720h"}},
+				{ArgumentSourceObservedConfig, "cluster-signing-duration", []string{"240h"}},
+				{ArgumentSourceDefaultConfig, "cluster-signing-duration", []string{"720h"}},
+			},
+			expected: []string{"--cluster-signing-duration=240h"},
+		},
+		{
+			name: "override of an observed flag conflicts",
+			adds: []add{
+				{ArgumentSourceDefaultConfig, "cluster-signing-duration", []string{"720h"}},
+				{ArgumentSourceObservedConfig, "cluster-signing-duration", []string{"240h"}},
+				{ArgumentSourceUnsupportedConfigOverrides, "cluster-signing-duration", []string{"480h"}},
+			},
+			expectedError: `flag --cluster-signing-duration set by unsupportedConfigOverrides to "480h" conflicts with "240h" set by observedConfig`,
+		},
+		{
+			name: "whitespace in value",
+			adds: []add{
+				{ArgumentSourceConfig, "cluster-name", []string{"foo bar"}},
+			},
+			expectedError: `extendedArguments: flag --cluster-name has invalid value "foo bar"`,
+		},
+		{
+			name: "command substitution in value",
+			adds: []add{
+				{ArgumentSourceConfig, "cluster-name", []string{"$(id)"}},
+			},
+			expectedError: `extendedArguments: flag --cluster-name has invalid value "$(id)"`,
+		},
+		{
+			name: "command separator in value",
+			adds: []add{
+				{ArgumentSourceConfig, "cluster-name", []string{"foo;reboot"}},
+			},
+			expectedError: `extendedArguments: flag --cluster-name has invalid value "foo;reboot"`,
+		},
+		{
+			name: "dashes in name",
+			adds: []add{
+				{ArgumentSourceConfig, "--cluster-name", []string{"foo"}},
+			},
+			expectedError: `extendedArguments: invalid flag name "--cluster-name"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			args := NewArguments()
+			var err error
+			for _, a := range test.adds {
+				if err = args.Add(a.source, a.name, a.values...); err != nil {
+					break
+				}
+			}
+			switch {
+			case err != nil && len(test.expectedError) == 0:
+				t.Fatalf("unexpected error: %v", err)
+			case err == nil && len(test.expectedError) != 0:
+				t.Fatalf("expected error %q, got none", test.expectedError)
+			case err != nil && !strings.Contains(err.Error(), test.expectedError):
+				t.Fatalf("expected error %q, got %v", test.expectedError, err)
+			case err != nil:
+				return
+			}
+			if actual := args.Strings(); !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestAddExtendedArguments(t *testing.T) {
+	args := NewArguments()
+	if err := args.Add(ArgumentSourceServingInfo, "tls-cipher-suites", "A,B"); err != nil {
+		t.Fatal(err)
+	}
+	err := addExtendedArguments(args, ArgumentSourceConfig, map[string]interface{}{
+		"extendedArguments": map[string]interface{}{
+			"tls-cipher-suites": []interface{}{"C"},
+			"kube-api-qps":      "150",
+			"feature-gates":     []interface{}{},
+			"cluster-name":      []interface{}{"foo"},
+		},
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, expected := range []string{
+		"extendedArguments.kube-api-qps must be a list of strings, got string",
+		`flag --tls-cipher-suites set by extendedArguments to "C" conflicts with "A,B" set by servingInfo`,
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected %q in %q", expected, err.Error())
+		}
+	}
+	if actual, expected := args.Strings(), []string{"--cluster-name=foo", "--tls-cipher-suites=A,B"}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestParseKubeControllerManagerCommand(t *testing.T) {
+	pod := resourceread.ReadPodV1OrDie(bindata.MustAsset("assets/kube-controller-manager/pod.yaml"))
+	script := strings.TrimSpace(pod.Spec.Containers[0].Args[0])
+
+	args, err := parseKubeControllerManagerCommand(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"--openshift-config=/etc/kubernetes/static-pod-resources/configmaps/config/config.yaml",
+		"--kubeconfig=/etc/kubernetes/static-pod-resources/configmaps/controller-manager-kubeconfig/kubeconfig",
+		"--authentication-kubeconfig=/etc/kubernetes/static-pod-resources/configmaps/controller-manager-kubeconfig/kubeconfig",
+		"--authorization-kubeconfig=/etc/kubernetes/static-pod-resources/configmaps/controller-manager-kubeconfig/kubeconfig",
+		"--client-ca-file=/etc/kubernetes/static-pod-certs/configmaps/client-ca/ca-bundle.crt",
+		"--requestheader-client-ca-file=/etc/kubernetes/static-pod-certs/configmaps/aggregator-client-ca/ca-bundle.crt",
+	}
+	if actual := args.Strings(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+
+	// the script is kept as is, only the flags that are not in the manifest are appended
+	if rendered := renderKubeControllerManagerCommand(script, args); rendered != script {
+		t.Errorf("expected the script unchanged, got:\n%s", rendered)
+	}
+	if err := args.Add(ArgumentSourceLogLevel, "v", "2"); err != nil {
+		t.Fatal(err)
+	}
+	if rendered := renderKubeControllerManagerCommand(script, args); rendered != script+" -v=2" {
+		t.Errorf("expected -v=2 appended to the script, got:\n%s", rendered)
+	}
+
+	if _, err := parseKubeControllerManagerCommand("exec hyperkube kube-controller-manager --foo"); err == nil {
+		t.Errorf("expected error for flag without value")
+	}
+	if _, err := parseKubeControllerManagerCommand("exec kube-controller-manager --foo=bar"); err == nil {
+		t.Errorf("expected error for missing exec")
+	}
+}
+
+func TestAddConfigExtendedArguments(t *testing.T) {
+	operatorSpec := &operatorv1.StaticPodOperatorSpec{
+		OperatorSpec: operatorv1.OperatorSpec{
+			ObservedConfig:             runtime.RawExtension{Raw: []byte(`{"extendedArguments": {"kube-api-qps": ["200"], "cluster-name": ["foo"]}}`)},
+			UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(`{"extendedArguments": {"kube-api-burst": ["400"], "cluster-name": ["foo"]}}`)},
+		},
+	}
+	args := NewArguments()
+	if err := addConfigExtendedArguments(args, operatorSpec); err != nil {
+		t.Fatal(err)
+	}
+	for name, expected := range map[string]Argument{
+		"kube-api-qps":   {Name: "kube-api-qps", Value: "200", Source: ArgumentSourceObservedConfig},
+		"kube-api-burst": {Name: "kube-api-burst", Value: "400", Source: ArgumentSourceUnsupportedConfigOverrides},
+		"cluster-name":   {Name: "cluster-name", Value: "foo", Source: ArgumentSourceObservedConfig},
+		"leader-elect":   {Name: "leader-elect", Value: "true", Source: ArgumentSourceDefaultConfig},
+	} {
+		if actual := args.Get(name); !reflect.DeepEqual(actual, []Argument{expected}) {
+			t.Errorf("expected %v, got %v", expected, actual)
+		}
+	}
+
+	// an override of an observed flag is neither rendered into the pod nor into the config
+	operatorSpec.UnsupportedConfigOverrides.Raw = []byte(`{"extendedArguments": {"kube-api-qps": ["300"]}}`)
+	expectedError := `flag --kube-api-qps set by unsupportedConfigOverrides to "300" conflicts with "200" set by observedConfig`
+	if err := addConfigExtendedArguments(NewArguments(), operatorSpec); err == nil || !strings.Contains(err.Error(), expectedError) {
+		t.Errorf("expected error %q, got %v", expectedError, err)
+	}
+	if _, err := renderKubeControllerManagerConfig(operatorSpec); err == nil || !strings.Contains(err.Error(), expectedError) {
+		t.Errorf("expected error %q, got %v", expectedError, err)
+	}
+}
@@ -0,0 +1,86 @@
+package targetconfigcontroller
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/klog/v2"
+
+	configv1 "github.com/openshift/api/config/v1"
+	leaderelectionconverter "github.com/openshift/library-go/pkg/config/leaderelection"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/operatorclient"
+)
+
+// CSRSignerLeaseName is the lease in the operator namespace held by the single actor managing the csr-signer, the
+// csr-signer-ca and the csr-controller-ca: the operator or one of the cert-recovery-controllers of the masters.
+const CSRSignerLeaseName = "kube-controller-manager-csr-signer"
+
+// CSRSignerLease competes for the csr-signer lease.
+type CSRSignerLease struct {
+	elector *leaderelection.LeaderElector
+}
+
+// NewCSRSignerLease returns the csr-signer lease of the component with the leader election timings used across
+// openshift. onStartedLeading is called whenever the lease is acquired.
+func NewCSRSignerLease(kubeClient kubernetes.Interface, component string, onStartedLeading func()) (*CSRSignerLease, error) {
+	return newCSRSignerLease(kubeClient, component, leaderelectionconverter.LeaderElectionDefaulting(configv1.LeaderElection{}, operatorclient.OperatorNamespace, CSRSignerLeaseName), onStartedLeading)
+}
+
+func newCSRSignerLease(kubeClient kubernetes.Interface, component string, config configv1.LeaderElection, onStartedLeading func()) (*CSRSignerLease, error) {
+	identity := component + "_" + string(uuid.NewUUID())
+	if hostname, err := os.Hostname(); err == nil {
+		identity = hostname + "_" + identity
+	}
+	lock, err := resourcelock.New(
+		resourcelock.LeasesResourceLock,
+		config.Namespace,
+		config.Name,
+		kubeClient.CoreV1(),
+		kubeClient.CoordinationV1(),
+		resourcelock.ResourceLockConfig{Identity: identity},
+	)
+	if err != nil {
+		return nil, err
+	}
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		ReleaseOnCancel: true,
+		LeaseDuration:   config.LeaseDuration.Duration,
+		RenewDeadline:   config.RenewDeadline.Duration,
+		RetryPeriod:     config.RetryPeriod.Duration,
+		Name:            CSRSignerLeaseName,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				klog.Infof("Acquired lease %s/%s as %s, managing the CSR signer", config.Namespace, config.Name, identity)
+				if onStartedLeading != nil {
+					onStartedLeading()
+				}
+			},
+			OnStoppedLeading: func() {
+				klog.Infof("Not holding lease %s/%s as %s", config.Namespace, config.Name, identity)
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &CSRSignerLease{elector: elector}, nil
+}
+
+// Run competes for the lease until the context is done, a lost lease is competed for again. The lease is released
+// when the context is done.
+func (l *CSRSignerLease) Run(ctx context.Context) {
+	wait.UntilWithContext(ctx, l.elector.Run, time.Second)
+}
+
+// IsLeader returns whether the lease is held.
+func (l *CSRSignerLease) IsLeader() bool {
+	return l.elector.IsLeader()
+}
@@ -0,0 +1,70 @@
+package targetconfigcontroller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/operatorclient"
+)
+
+func TestCSRSignerLease(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	config := configv1.LeaderElection{
+		Namespace:     operatorclient.OperatorNamespace,
+		Name:          CSRSignerLeaseName,
+		LeaseDuration: metav1.Duration{Duration: 3 * time.Second},
+		RenewDeadline: metav1.Duration{Duration: 2 * time.Second},
+		RetryPeriod:   metav1.Duration{Duration: 200 * time.Millisecond},
+	}
+	started := make(chan string, 2)
+	newLease := func(component string) *CSRSignerLease {
+		lease, err := newCSRSignerLease(kubeClient, component, config, func() { started <- component })
+		if err != nil {
+			t.Fatal(err)
+		}
+		return lease
+	}
+	operator, recovery := newLease("operator"), newLease("recovery")
+
+	operatorCtx, operatorCancel := context.WithCancel(context.Background())
+	operatorDone := make(chan struct{})
+	go func() {
+		defer close(operatorDone)
+		operator.Run(operatorCtx)
+	}()
+	if component := <-started; component != "operator" {
+		t.Fatalf("expected the operator to acquire the lease, got %s", component)
+	}
+
+	recoveryCtx, recoveryCancel := context.WithCancel(context.Background())
+	defer recoveryCancel()
+	go recovery.Run(recoveryCtx)
+	// the recovery controller competes once it observed the lease held by the operator
+	if err := wait.PollUntilContextTimeout(context.Background(), 100*time.Millisecond, 5*time.Second, true, func(context.Context) (bool, error) {
+		return len(recovery.elector.GetLeader()) > 0, nil
+	}); err != nil {
+		t.Fatalf("expected the recovery controller to observe the lease: %v", err)
+	}
+	if !operator.IsLeader() || recovery.IsLeader() {
+		t.Fatalf("expected only the operator to hold the lease, operator %v, recovery %v", operator.IsLeader(), recovery.IsLeader())
+	}
+
+	// a clean shutdown releases the lease right away
+	operatorCancel()
+	<-operatorDone
+	if err := wait.PollUntilContextTimeout(context.Background(), 100*time.Millisecond, 2*time.Second, true, func(context.Context) (bool, error) {
+		return recovery.IsLeader(), nil
+	}); err != nil {
+		t.Fatalf("expected the recovery controller to take over the released lease: %v", err)
+	}
+	if component := <-started; component != "recovery" {
+		t.Errorf("expected the recovery controller to be notified, got %s", component)
+	}
+}
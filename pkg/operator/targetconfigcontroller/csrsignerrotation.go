@@ -0,0 +1,253 @@
+package targetconfigcontroller
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/util/cert"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/certrotation"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/operatorclient"
+)
+
+const (
+	// RotateCSRSignerAnnotation on the kubecontrollermanager/cluster resource requests an immediate rotation of the
+	// csr-signer, e.g. after a suspected key compromise. Its value identifies the request, setting a new value requests
+	// another rotation. The previous signer is not revoked, it stays in the CA bundles until it expires so that the
+	// certificates it issued keep working.
+	RotateCSRSignerAnnotation = "kube-controller-manager.openshift.io/rotate-csr-signer"
+
+	// RotateCSRSignerSignerAnnotation set to true extends the requested rotation to the csr-signer-signer, which issues
+	// the csr-signer.
+	RotateCSRSignerSignerAnnotation = "kube-controller-manager.openshift.io/rotate-csr-signer-signer"
+
+	// csrSignerRotationRequestAnnotation on the csr-signer and csr-signer-signer in the operator namespace records the
+	// last request they were rotated for, so that every request is handled once.
+	csrSignerRotationRequestAnnotation = "kube-controller-manager.openshift.io/rotation-request"
+)
+
+// csrSignerRotationRequest is an on-demand rotation requested on the kubecontrollermanager/cluster resource.
+type csrSignerRotationRequest struct {
+	id           string
+	signerSigner bool
+}
+
+func csrSignerRotationRequested(annotations map[string]string) (csrSignerRotationRequest, bool) {
+	id := annotations[RotateCSRSignerAnnotation]
+	if len(id) == 0 {
+		return csrSignerRotationRequest{}, false
+	}
+	return csrSignerRotationRequest{id: id, signerSigner: annotations[RotateCSRSignerSignerAnnotation] == "true"}, true
+}
+
+// manageCSRSignerRotation forces the cert rotation controller to rotate the csr-signer, and the csr-signer-signer if
+// requested, by removing the not-after annotation it rotates on. The new signer then goes through the same trust
+// propagation as a scheduled rotation. The progress is reported in the CSRSignerRotationPending condition and an event
+// is emitted once the kube-controller-manager signs with the new signer and it is published to the kube-apiserver.
+func manageCSRSignerRotation(ctx context.Context, c TargetConfigController, recorder events.Recorder, annotations map[string]string) error {
+	request, requested := csrSignerRotationRequested(annotations)
+	if !requested {
+		return updateCSRSignerRotationCondition(ctx, c, recorder, operatorv1.OperatorCondition{
+			Type:   "CSRSignerRotationPending",
+			Status: operatorv1.ConditionFalse,
+			Reason: "AsExpected",
+		})
+	}
+
+	signer, err := c.secretLister.Secrets(operatorclient.OperatorNamespace).Get("csr-signer")
+	if err != nil {
+		return err
+	}
+	signerSigner, err := c.secretLister.Secrets(operatorclient.OperatorNamespace).Get("csr-signer-signer")
+	if err != nil {
+		return err
+	}
+
+	// the csr-signer-signer goes first, the csr-signer is issued again once it is rotated
+	triggered := false
+	if request.signerSigner && signerSigner.Annotations[csrSignerRotationRequestAnnotation] != request.id {
+		updated, err := forceCertRotation(ctx, c.kubeClient.CoreV1(), signerSigner, request.id, true)
+		if err != nil {
+			return err
+		}
+		triggered = triggered || updated
+	}
+	if signer.Annotations[csrSignerRotationRequestAnnotation] != request.id {
+		updated, err := forceCertRotation(ctx, c.kubeClient.CoreV1(), signer, request.id, !request.signerSigner)
+		if err != nil {
+			return err
+		}
+		triggered = triggered || updated
+	}
+	if triggered {
+		recorder.Eventf("CSRSignerRotationRequested", "Rotating %s for request %q, %s", csrSignerRotationScope(request), request.id, previousSignerTrust(request))
+		return updateCSRSignerRotationCondition(ctx, c, recorder, csrSignerRotationCondition(request, "Rotating", fmt.Sprintf("waiting for %s to be rotated", csrSignerRotationScope(request))))
+	}
+	if signer.Annotations[csrSignerRotationRequestAnnotation] != request.id || (request.signerSigner && signerSigner.Annotations[csrSignerRotationRequestAnnotation] != request.id) {
+		// the update conflicted with a newer version of the secrets, retry on the next sync
+		return nil
+	}
+
+	target, err := c.secretLister.Secrets(operatorclient.TargetNamespace).Get("csr-signer")
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	_, err = c.secretLister.Secrets(operatorclient.OperatorNamespace).Get(userCSRSignerName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	userSignerInUse := err == nil
+	trustBundle, err := c.configMapLister.ConfigMaps(operatorclient.GlobalMachineSpecifiedConfigNamespace).Get("csr-controller-ca")
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	reason, message, reissue := csrSignerRotationProgress(request, signer, signerSigner, target, trustBundle, userSignerInUse)
+	if reissue {
+		// the csr-signer is still issued by the previous csr-signer-signer
+		if _, err := forceCertRotation(ctx, c.kubeClient.CoreV1(), signer, request.id, true); err != nil {
+			return err
+		}
+	}
+	return updateCSRSignerRotationCondition(ctx, c, recorder, csrSignerRotationCondition(request, reason, message))
+}
+
+// csrSignerRotationProgress returns the reason and message of the phase the rotation is in and whether the csr-signer
+// must be issued again by the rotated csr-signer-signer.
+func csrSignerRotationProgress(request csrSignerRotationRequest, signer, signerSigner, target *corev1.Secret, trustBundle *corev1.ConfigMap, userSignerInUse bool) (string, string, bool) {
+	if len(signer.Annotations[certrotation.CertificateNotAfterAnnotation]) == 0 {
+		return "Rotating", fmt.Sprintf("waiting for %s to be rotated", csrSignerRotationScope(request)), false
+	}
+	signerCert, err := firstCertificate(signer.Data[corev1.TLSCertKey])
+	if err != nil {
+		return "Rotating", fmt.Sprintf("secret/csr-signer: %v", err), false
+	}
+
+	trusted := []*x509.Certificate{signerCert}
+	if request.signerSigner {
+		if len(signerSigner.Annotations[certrotation.CertificateNotAfterAnnotation]) == 0 {
+			return "Rotating", fmt.Sprintf("waiting for %s to be rotated", csrSignerRotationScope(request)), false
+		}
+		signerSignerCert, err := firstCertificate(signerSigner.Data[corev1.TLSCertKey])
+		if err != nil {
+			return "Rotating", fmt.Sprintf("secret/csr-signer-signer: %v", err), false
+		}
+		if signerCert.Issuer.CommonName != signerSignerCert.Subject.CommonName {
+			return "Rotating", fmt.Sprintf("waiting for csr-signer to be issued by %q", signerSignerCert.Subject.CommonName), true
+		}
+		trusted = append(trusted, signerSignerCert)
+	}
+
+	for _, required := range trusted {
+		if trustBundle == nil || !bundleContains(trustBundle.Data["ca-bundle.crt"], required) {
+			return "WaitingForTrust", fmt.Sprintf("waiting for %q to be published in configmap/csr-controller-ca -n %s", required.Subject.CommonName, operatorclient.GlobalMachineSpecifiedConfigNamespace), false
+		}
+	}
+
+	if userSignerInUse {
+		return "Completed", fmt.Sprintf("%q is trusted, the kube-controller-manager keeps signing with the user supplied signer from secret/%s -n %s, %s", signerCert.Subject.CommonName, UserCSRSignerSecretName, operatorclient.GlobalUserSpecifiedConfigNamespace, previousSignerTrust(request)), false
+	}
+	if target == nil {
+		return "WaitingForPromotion", fmt.Sprintf("waiting for the kube-controller-manager to sign with %q", signerCert.Subject.CommonName), false
+	}
+	if targetCert, err := firstCertificate(target.Data[corev1.TLSCertKey]); err != nil || !targetCert.Equal(signerCert) {
+		return "WaitingForPromotion", fmt.Sprintf("waiting for the kube-controller-manager to sign with %q", signerCert.Subject.CommonName), false
+	}
+	return "Completed", fmt.Sprintf("the kube-controller-manager signs with %q, %s", signerCert.Subject.CommonName, previousSignerTrust(request)), false
+}
+
+func csrSignerRotationScope(request csrSignerRotationRequest) string {
+	if request.signerSigner {
+		return "csr-signer-signer and csr-signer"
+	}
+	return "csr-signer"
+}
+
+// previousSignerTrust explains that the rotation does not revoke the previous signer.
+func previousSignerTrust(request csrSignerRotationRequest) string {
+	return fmt.Sprintf("the previous %s stays trusted in configmap/csr-signer-ca -n %s and configmap/csr-controller-ca -n %s until it expires",
+		csrSignerRotationScope(request), operatorclient.OperatorNamespace, operatorclient.GlobalMachineSpecifiedConfigNamespace)
+}
+
+func csrSignerRotationCondition(request csrSignerRotationRequest, reason, message string) operatorv1.OperatorCondition {
+	status := operatorv1.ConditionTrue
+	if reason == "Completed" {
+		status = operatorv1.ConditionFalse
+	}
+	return operatorv1.OperatorCondition{
+		Type:    "CSRSignerRotationPending",
+		Status:  status,
+		Reason:  reason,
+		Message: fmt.Sprintf("rotation request %q: %s", request.id, message),
+	}
+}
+
+// updateCSRSignerRotationCondition emits an event when a rotation completes.
+func updateCSRSignerRotationCondition(ctx context.Context, c TargetConfigController, recorder events.Recorder, condition operatorv1.OperatorCondition) error {
+	if condition.Reason == "Completed" {
+		_, status, _, err := c.operatorClient.GetStaticPodOperatorState()
+		if err != nil {
+			return err
+		}
+		if existing := v1helpers.FindOperatorCondition(status.Conditions, condition.Type); existing == nil || existing.Reason != condition.Reason || existing.Message != condition.Message {
+			recorder.Eventf("CSRSignerRotationCompleted", "CSR signer %s", condition.Message)
+		}
+	}
+	_, _, err := v1helpers.UpdateStaticPodStatus(ctx, c.operatorClient, v1helpers.UpdateStaticPodConditionFn(condition))
+	return err
+}
+
+// forceCertRotation records the rotation request on a secret managed by the cert rotation controller and removes its
+// not-after annotation if it must be rotated, the cert rotation controller then issues a new certificate. Nothing is
+// updated if the secret already waits to be rotated for the request. A conflict means the secret was read from a stale
+// cache, it is left to the next sync.
+func forceCertRotation(ctx context.Context, client corev1client.SecretsGetter, secret *corev1.Secret, requestID string, rotate bool) (bool, error) {
+	_, hasNotAfter := secret.Annotations[certrotation.CertificateNotAfterAnnotation]
+	if secret.Annotations[csrSignerRotationRequestAnnotation] == requestID && (!rotate || !hasNotAfter) {
+		return false, nil
+	}
+	secret = secret.DeepCopy()
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[csrSignerRotationRequestAnnotation] = requestID
+	if rotate {
+		delete(secret.Annotations, certrotation.CertificateNotAfterAnnotation)
+	}
+	_, err := client.Secrets(secret.Namespace).Update(ctx, secret, metav1.UpdateOptions{})
+	if apierrors.IsConflict(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func firstCertificate(pemBytes []byte) (*x509.Certificate, error) {
+	certificates, err := cert.ParseCertsPEM(pemBytes)
+	if err != nil {
+		return nil, err
+	}
+	return certificates[0], nil
+}
+
+func bundleContains(bundle string, required *x509.Certificate) bool {
+	certificates, err := cert.ParseCertsPEM([]byte(bundle))
+	if err != nil {
+		return false
+	}
+	for _, certificate := range certificates {
+		if bytes.Equal(certificate.Raw, required.Raw) {
+			return true
+		}
+	}
+	return false
+}
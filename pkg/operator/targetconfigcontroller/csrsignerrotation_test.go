@@ -0,0 +1,320 @@
+package targetconfigcontroller
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/clock"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/openshift/library-go/pkg/operator/certrotation"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/operatorclient"
+)
+
+// makeRotatedSecret returns a secret like the cert rotation controller manages, holding a CA named commonName issued by
+// the given parent, or self-signed without one.
+func makeRotatedSecret(t *testing.T, namespace, name, commonName string, parent *crypto.TLSCertificateConfig) (*corev1.Secret, *crypto.TLSCertificateConfig) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: commonName},
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(30 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	issuer, issuerKey := template, interface{}(key)
+	if parent != nil {
+		issuer, issuerKey = parent.Certs[0], parent.Key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, &key.PublicKey, issuerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &crypto.TLSCertificateConfig{Certs: []*x509.Certificate{certificate}, Key: key}
+	certBytes, keyBytes, err := config.GetPEMBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+			Annotations: map[string]string{
+				certrotation.CertificateNotBeforeAnnotation: certificate.NotBefore.Format(time.RFC3339),
+				certrotation.CertificateNotAfterAnnotation:  certificate.NotAfter.Format(time.RFC3339),
+			},
+		},
+		Data: map[string][]byte{"tls.crt": certBytes, "tls.key": keyBytes},
+	}, config
+}
+
+func TestCSRSignerRotationProgress(t *testing.T) {
+	signerSigner, signerSignerConfig := makeRotatedSecret(t, operatorclient.OperatorNamespace, "csr-signer-signer", "csr-signer-signer@2", nil)
+	signer, _ := makeRotatedSecret(t, operatorclient.OperatorNamespace, "csr-signer", "kube-csr-signer@2", signerSignerConfig)
+	oldSigner, _ := makeRotatedSecret(t, operatorclient.OperatorNamespace, "csr-signer", "kube-csr-signer@1", nil)
+	target := signer.DeepCopy()
+	target.Namespace = operatorclient.TargetNamespace
+	oldTarget := oldSigner.DeepCopy()
+	oldTarget.Namespace = operatorclient.TargetNamespace
+
+	rotating := signer.DeepCopy()
+	delete(rotating.Annotations, certrotation.CertificateNotAfterAnnotation)
+	rotatingSignerSigner := signerSigner.DeepCopy()
+	delete(rotatingSignerSigner.Annotations, certrotation.CertificateNotAfterAnnotation)
+
+	bundle := func(secrets ...*corev1.Secret) *corev1.ConfigMap {
+		configMap := &corev1.ConfigMap{Data: map[string]string{"ca-bundle.crt": ""}}
+		for _, secret := range secrets {
+			configMap.Data["ca-bundle.crt"] += string(secret.Data["tls.crt"])
+		}
+		return configMap
+	}
+
+	tests := []struct {
+		name            string
+		request         csrSignerRotationRequest
+		signer          *corev1.Secret
+		signerSigner    *corev1.Secret
+		target          *corev1.Secret
+		trustBundle     *corev1.ConfigMap
+		userSignerInUse bool
+		expectedReason  string
+		expectedReissue bool
+	}{
+		{
+			name:           "csr-signer not rotated yet",
+			request:        csrSignerRotationRequest{id: "1"},
+			signer:         rotating,
+			signerSigner:   signerSigner,
+			target:         oldTarget,
+			expectedReason: "Rotating",
+		},
+		{
+			name:           "csr-signer-signer not rotated yet",
+			request:        csrSignerRotationRequest{id: "1", signerSigner: true},
+			signer:         oldSigner,
+			signerSigner:   rotatingSignerSigner,
+			target:         oldTarget,
+			expectedReason: "Rotating",
+		},
+		{
+			name:            "csr-signer issued by the previous csr-signer-signer",
+			request:         csrSignerRotationRequest{id: "1", signerSigner: true},
+			signer:          oldSigner,
+			signerSigner:    signerSigner,
+			target:          oldTarget,
+			expectedReason:  "Rotating",
+			expectedReissue: true,
+		},
+		{
+			name:           "new csr-signer not published",
+			request:        csrSignerRotationRequest{id: "1"},
+			signer:         signer,
+			signerSigner:   signerSigner,
+			target:         oldTarget,
+			trustBundle:    bundle(oldSigner),
+			expectedReason: "WaitingForTrust",
+		},
+		{
+			name:           "new csr-signer-signer not published",
+			request:        csrSignerRotationRequest{id: "1", signerSigner: true},
+			signer:         signer,
+			signerSigner:   signerSigner,
+			target:         oldTarget,
+			trustBundle:    bundle(oldSigner, signer),
+			expectedReason: "WaitingForTrust",
+		},
+		{
+			name:           "new csr-signer trusted but not used yet",
+			request:        csrSignerRotationRequest{id: "1"},
+			signer:         signer,
+			signerSigner:   signerSigner,
+			target:         oldTarget,
+			trustBundle:    bundle(oldSigner, signer),
+			expectedReason: "WaitingForPromotion",
+		},
+		{
+			name:           "new csr-signer in use",
+			request:        csrSignerRotationRequest{id: "1"},
+			signer:         signer,
+			signerSigner:   signerSigner,
+			target:         target,
+			trustBundle:    bundle(oldSigner, signer),
+			expectedReason: "Completed",
+		},
+		{
+			name:           "new csr-signer-signer and csr-signer in use",
+			request:        csrSignerRotationRequest{id: "1", signerSigner: true},
+			signer:         signer,
+			signerSigner:   signerSigner,
+			target:         target,
+			trustBundle:    bundle(oldSigner, signer, signerSigner),
+			expectedReason: "Completed",
+		},
+		{
+			name:            "user supplied signer in use",
+			request:         csrSignerRotationRequest{id: "1"},
+			signer:          signer,
+			signerSigner:    signerSigner,
+			target:          oldTarget,
+			trustBundle:     bundle(oldSigner, signer),
+			userSignerInUse: true,
+			expectedReason:  "Completed",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reason, message, reissue := csrSignerRotationProgress(test.request, test.signer, test.signerSigner, test.target, test.trustBundle, test.userSignerInUse)
+			if reason != test.expectedReason || reissue != test.expectedReissue {
+				t.Errorf("expected %s with reissue %v, got %s with reissue %v: %s", test.expectedReason, test.expectedReissue, reason, reissue, message)
+			}
+			if reason == "Completed" && !strings.Contains(message, "stays trusted") {
+				t.Errorf("expected the completed message to state that the previous signer stays trusted, got %s", message)
+			}
+		})
+	}
+}
+
+func TestManageCSRSignerRotation(t *testing.T) {
+	signerSigner, signerSignerConfig := makeRotatedSecret(t, operatorclient.OperatorNamespace, "csr-signer-signer", "csr-signer-signer@1", nil)
+	signer, _ := makeRotatedSecret(t, operatorclient.OperatorNamespace, "csr-signer", "kube-csr-signer@1", signerSignerConfig)
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, secret := range []*corev1.Secret{signerSigner, signer} {
+		if err := indexer.Add(secret); err != nil {
+			t.Fatal(err)
+		}
+	}
+	client := fake.NewSimpleClientset(signerSigner, signer)
+	operatorClient := v1helpers.NewFakeStaticPodOperatorClient(&operatorv1.StaticPodOperatorSpec{}, &operatorv1.StaticPodOperatorStatus{}, nil, nil)
+	c := TargetConfigController{
+		secretLister:    corev1listers.NewSecretLister(indexer),
+		configMapLister: corev1listers.NewConfigMapLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})),
+		kubeClient:      client,
+		operatorClient:  operatorClient,
+	}
+	recorder := events.NewInMemoryRecorder("target-config-controller", clock.RealClock{})
+	annotations := map[string]string{
+		RotateCSRSignerAnnotation:       "compromise-1",
+		RotateCSRSignerSignerAnnotation: "true",
+	}
+
+	if err := manageCSRSignerRotation(context.TODO(), c, recorder, annotations); err != nil {
+		t.Fatal(err)
+	}
+	// the csr-signer-signer is rotated, the csr-signer only records the request until then
+	rotatedSignerSigner, err := client.CoreV1().Secrets(operatorclient.OperatorNamespace).Get(context.TODO(), "csr-signer-signer", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := rotatedSignerSigner.Annotations[certrotation.CertificateNotAfterAnnotation]; ok || rotatedSignerSigner.Annotations[csrSignerRotationRequestAnnotation] != "compromise-1" {
+		t.Errorf("expected the csr-signer-signer to be rotated, got annotations %v", rotatedSignerSigner.Annotations)
+	}
+	recordedSigner, err := client.CoreV1().Secrets(operatorclient.OperatorNamespace).Get(context.TODO(), "csr-signer", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := recordedSigner.Annotations[certrotation.CertificateNotAfterAnnotation]; !ok || recordedSigner.Annotations[csrSignerRotationRequestAnnotation] != "compromise-1" {
+		t.Errorf("expected the csr-signer to record the request, got annotations %v", recordedSigner.Annotations)
+	}
+	_, status, _, err := operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	condition := v1helpers.FindOperatorCondition(status.Conditions, "CSRSignerRotationPending")
+	if condition == nil || condition.Status != operatorv1.ConditionTrue || condition.Reason != "Rotating" {
+		t.Errorf("expected the rotation to be pending, got %v", condition)
+	}
+
+	// the same request is not handled twice
+	for _, secret := range []*corev1.Secret{rotatedSignerSigner, recordedSigner} {
+		if err := indexer.Update(secret); err != nil {
+			t.Fatal(err)
+		}
+	}
+	client.ClearActions()
+	if err := manageCSRSignerRotation(context.TODO(), c, recorder, annotations); err != nil {
+		t.Fatal(err)
+	}
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "update" {
+			t.Errorf("expected no update while the csr-signer-signer is rotated, got %v", action)
+		}
+	}
+}
+
+func TestForceCertRotation(t *testing.T) {
+	signer, _ := makeRotatedSecret(t, operatorclient.OperatorNamespace, "csr-signer", "kube-csr-signer@1", nil)
+	recorded := signer.DeepCopy()
+	recorded.Annotations[csrSignerRotationRequestAnnotation] = "1"
+	rotating := recorded.DeepCopy()
+	delete(rotating.Annotations, certrotation.CertificateNotAfterAnnotation)
+
+	tests := []struct {
+		name             string
+		secret           *corev1.Secret
+		rotate           bool
+		expectedUpdate   bool
+		expectedNotAfter bool
+	}{
+		{name: "new request", secret: signer, rotate: true, expectedUpdate: true},
+		{name: "new request recorded only", secret: signer, expectedUpdate: true, expectedNotAfter: true},
+		{name: "request already recorded", secret: recorded, expectedNotAfter: true},
+		{name: "rotation already requested", secret: rotating, rotate: true},
+		{name: "reissue after rotation", secret: recorded, rotate: true, expectedUpdate: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(test.secret)
+			updated, err := forceCertRotation(context.TODO(), client.CoreV1(), test.secret, "1", test.rotate)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if updated != test.expectedUpdate {
+				t.Errorf("expected updated %v, got %v", test.expectedUpdate, updated)
+			}
+			updates := 0
+			for _, action := range client.Actions() {
+				if action.GetVerb() == "update" {
+					updates++
+				}
+			}
+			if test.expectedUpdate != (updates > 0) {
+				t.Errorf("expected update %v, got %d updates", test.expectedUpdate, updates)
+			}
+			secret, err := client.CoreV1().Secrets(operatorclient.OperatorNamespace).Get(context.TODO(), "csr-signer", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := secret.Annotations[certrotation.CertificateNotAfterAnnotation]; ok != test.expectedNotAfter || secret.Annotations[csrSignerRotationRequestAnnotation] != "1" {
+				t.Errorf("unexpected annotations %v", secret.Annotations)
+			}
+		})
+	}
+}
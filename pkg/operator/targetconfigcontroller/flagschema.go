@@ -0,0 +1,169 @@
+package targetconfigcontroller
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/bindata"
+)
+
+// FlagType is the value type of a kube-controller-manager flag.
+type FlagType string
+
+const (
+	FlagTypeString          FlagType = "string"
+	FlagTypeBool            FlagType = "bool"
+	FlagTypeInt             FlagType = "int"
+	FlagTypeFloat           FlagType = "float"
+	FlagTypeDuration        FlagType = "duration"
+	FlagTypeStringSlice     FlagType = "stringSlice"
+	FlagTypeMapStringBool   FlagType = "mapStringBool"
+	FlagTypeMapStringString FlagType = "mapStringString"
+)
+
+// FlagSchema describes the flags accepted by the kube-controller-manager of the payload.
+type FlagSchema struct {
+	Flags map[string]FlagType `json:"flags"`
+}
+
+// LoadFlagSchema reads the flag schema embedded in the operator.
+func LoadFlagSchema() (*FlagSchema, error) {
+	schema := &FlagSchema{}
+	if err := yaml.Unmarshal(bindata.MustAsset("assets/config/kube-controller-manager-flags.yaml"), schema); err != nil {
+		return nil, fmt.Errorf("failed to parse the kube-controller-manager flag schema: %w", err)
+	}
+	for name, flagType := range schema.Flags {
+		if _, err := parseFlagValue(flagType, ""); err == errUnknownFlagType {
+			return nil, fmt.Errorf("flag --%s in the kube-controller-manager flag schema has unknown type %q", name, flagType)
+		}
+	}
+	return schema, nil
+}
+
+// InvalidArgumentsError lists all kube-controller-manager flags that did not pass the schema validation.
+type InvalidArgumentsError struct {
+	// Reasons maps the offending flag to the reason it was rejected.
+	Reasons map[string]string
+}
+
+func (e *InvalidArgumentsError) Error() string {
+	flags := []string{}
+	for flag := range e.Reasons {
+		flags = append(flags, flag)
+	}
+	sort.Strings(flags)
+
+	messages := []string{}
+	for _, flag := range flags {
+		messages = append(messages, fmt.Sprintf("%s: %s", flag, e.Reasons[flag]))
+	}
+	return fmt.Sprintf("invalid kube-controller-manager flags: %s", strings.Join(messages, ", "))
+}
+
+// Validate checks every flag in args against the schema. It returns an *InvalidArgumentsError listing all offending flags.
+func (s *FlagSchema) Validate(args *Arguments) error {
+	reasons := map[string]string{}
+	for _, arg := range args.List() {
+		flagType, known := s.Flags[arg.Name]
+		if !known {
+			reasons[fmt.Sprintf("--%s (%s)", arg.Name, arg.Source)] = "unknown flag"
+			continue
+		}
+		if values := args.Get(arg.Name); len(values) > 1 && !flagType.repeatable() {
+			reasons[fmt.Sprintf("--%s (%s)", arg.Name, arg.Source)] = fmt.Sprintf("%s flag set %d times", flagType, len(values))
+			continue
+		}
+		if _, err := parseFlagValue(flagType, arg.Value); err != nil {
+			reasons[fmt.Sprintf("%s (%s)", arg, arg.Source)] = err.Error()
+		}
+	}
+
+	if len(reasons) > 0 {
+		return &InvalidArgumentsError{Reasons: reasons}
+	}
+	return nil
+}
+
+func (t FlagType) repeatable() bool {
+	switch t {
+	case FlagTypeStringSlice, FlagTypeMapStringBool, FlagTypeMapStringString:
+		return true
+	}
+	return false
+}
+
+var errUnknownFlagType = fmt.Errorf("unknown flag type")
+
+// parseFlagValue parses value the way the kube-controller-manager would. It returns the parsed value for callers
+// that need to compare flag values.
+func parseFlagValue(flagType FlagType, value string) (interface{}, error) {
+	switch flagType {
+	case FlagTypeString:
+		return value, nil
+	case FlagTypeBool:
+		ret, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bool %q", value)
+		}
+		return ret, nil
+	case FlagTypeInt:
+		ret, err := strconv.ParseInt(value, 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid int %q", value)
+		}
+		return ret, nil
+	case FlagTypeFloat:
+		ret, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid float %q", value)
+		}
+		return ret, nil
+	case FlagTypeDuration:
+		ret, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration %q", value)
+		}
+		return ret, nil
+	case FlagTypeStringSlice:
+		if len(value) == 0 {
+			return []string{}, nil
+		}
+		return strings.Split(value, ","), nil
+	case FlagTypeMapStringBool:
+		ret := map[string]bool{}
+		if len(value) == 0 {
+			return ret, nil
+		}
+		for _, pair := range strings.Split(value, ",") {
+			key, boolValue, found := strings.Cut(pair, "=")
+			if !found || len(key) == 0 {
+				return nil, fmt.Errorf("invalid key=bool pair %q", pair)
+			}
+			parsed, err := strconv.ParseBool(boolValue)
+			if err != nil {
+				return nil, fmt.Errorf("invalid bool %q for %s", boolValue, key)
+			}
+			ret[key] = parsed
+		}
+		return ret, nil
+	case FlagTypeMapStringString:
+		ret := map[string]string{}
+		if len(value) == 0 {
+			return ret, nil
+		}
+		for _, pair := range strings.Split(value, ",") {
+			key, stringValue, found := strings.Cut(pair, "=")
+			if !found || len(key) == 0 {
+				return nil, fmt.Errorf("invalid key=value pair %q", pair)
+			}
+			ret[key] = stringValue
+		}
+		return ret, nil
+	}
+	return nil, errUnknownFlagType
+}
@@ -0,0 +1,206 @@
+package targetconfigcontroller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/clock"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/bindata"
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/operatorclient"
+)
+
+func TestFlagSchemaCoversDefaults(t *testing.T) {
+	schema, err := LoadFlagSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pod := resourceread.ReadPodV1OrDie(bindata.MustAsset("assets/kube-controller-manager/pod.yaml"))
+	args, err := parseKubeControllerManagerCommand(pod.Spec.Containers[0].Args[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var defaultConfig map[string]interface{}
+	if err := yaml.Unmarshal(bindata.MustAsset("assets/config/defaultconfig.yaml"), &defaultConfig); err != nil {
+		t.Fatal(err)
+	}
+	if err := addExtendedArguments(args, ArgumentSourceConfig, defaultConfig); err != nil {
+		t.Fatal(err)
+	}
+
+	// flags set by config observers and managePod
+	for name, value := range map[string]string{
+		"cluster-name":              "foo-abcde",
+		"feature-gates":             "Foo=true,Bar=false",
+		"cluster-cidr":              "10.128.0.0/14",
+		"service-cluster-ip-range":  "172.30.0.0/16",
+		"node-monitor-grace-period": "40s",
+		"tls-cipher-suites":         "TLS_AES_128_GCM_SHA256,TLS_AES_256_GCM_SHA384",
+		"tls-min-version":           "VersionTLS12",
+		"tls-cert-file":             "/tls.crt",
+		"tls-private-key-file":      "/tls.key",
+		"v":                         "2",
+	} {
+		if err := args.Add("test", name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := schema.Validate(args); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFlagSchemaValidate(t *testing.T) {
+	schema := &FlagSchema{Flags: map[string]FlagType{
+		"kube-api-qps":             FlagTypeFloat,
+		"kube-api-burst":           FlagTypeInt,
+		"leader-elect":             FlagTypeBool,
+		"cluster-signing-duration": FlagTypeDuration,
+		"controllers":              FlagTypeStringSlice,
+		"feature-gates":            FlagTypeMapStringBool,
+		"cluster-name":             FlagTypeString,
+	}}
+
+	tests := []struct {
+		name          string
+		flags         map[string][]string
+		expectedError []string
+	}{
+		{
+			name: "valid",
+			flags: map[string][]string{
+				"kube-api-qps":             {"150.5"},
+				"kube-api-burst":           {"300"},
+				"leader-elect":             {"true"},
+				"cluster-signing-duration": {"720h"},
+				"controllers":              {"*", "-ttl"},
+				"feature-gates":            {"Foo=true", "Bar=false,Baz=true"},
+				"cluster-name":             {""},
+			},
+		},
+		{
+			name: "unknown flag",
+			flags: map[string][]string{
+				"kube-api-qsp": {"150"},
+			},
+			expectedError: []string{"--kube-api-qsp (extendedArguments): unknown flag"},
+		},
+		{
+			name: "bad values",
+			flags: map[string][]string{
+				"kube-api-qps":             {"fast"},
+				"kube-api-burst":           {"1.5"},
+				"leader-elect":             {"yes"},
+				"cluster-signing-duration": {"30d"},
+				"feature-gates":            {"Foo"},
+			},
+			expectedError: []string{
+				`--kube-api-qps=fast (extendedArguments): invalid float "fast"`,
+				`--kube-api-burst=1.5 (extendedArguments): invalid int "1.5"`,
+				`--leader-elect=yes (extendedArguments): invalid bool "yes"`,
+				`--cluster-signing-duration=30d (extendedArguments): invalid duration "30d"`,
+				`--feature-gates=Foo (extendedArguments): invalid key=bool pair "Foo"`,
+			},
+		},
+		{
+			name: "scalar set twice",
+			flags: map[string][]string{
+				"kube-api-burst": {"300", "400"},
+			},
+			expectedError: []string{"--kube-api-burst (extendedArguments): int flag set 2 times"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			args := NewArguments()
+			for name, values := range test.flags {
+				if err := args.Add(ArgumentSourceConfig, name, values...); err != nil {
+					t.Fatal(err)
+				}
+			}
+			err := schema.Validate(args)
+			if len(test.expectedError) == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if _, ok := err.(*InvalidArgumentsError); !ok {
+				t.Errorf("expected *InvalidArgumentsError, got %T", err)
+			}
+			for _, expected := range test.expectedError {
+				if !strings.Contains(err.Error(), expected) {
+					t.Errorf("expected %q in %q", expected, err.Error())
+				}
+			}
+		})
+	}
+}
+
+func TestManagePodRejectsInvalidFlags(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	operatorSpec := &operatorv1.StaticPodOperatorSpec{
+		OperatorSpec: operatorv1.OperatorSpec{
+			ObservedConfig: runtime.RawExtension{Raw: []byte(`{"extendedArguments": {"kube-api-qps": ["fast"], "cluster-name": ["foo"]}}`)},
+		},
+	}
+
+	_, _, err := managePod(context.TODO(), client.CoreV1(), client.CoreV1(), events.NewInMemoryRecorder("test", clock.RealClock{}), operatorSpec, "kcm", "operator", "cpc", "v1", false, true)
+	if err == nil || !strings.Contains(err.Error(), `--kube-api-qps=fast (observedConfig): invalid float "fast"`) {
+		t.Fatalf("expected invalid flag error, got %v", err)
+	}
+	if _, err := client.CoreV1().ConfigMaps(operatorclient.TargetNamespace).Get(context.TODO(), "kube-controller-manager-pod", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected kube-controller-manager-pod not to be written, got %v", err)
+	}
+
+	operatorSpec.ObservedConfig.Raw = []byte(`{"extendedArguments": {"kube-api-qps": ["100"], "cluster-name": ["foo"]}}`)
+	podConfigMap, _, err := managePod(context.TODO(), client.CoreV1(), client.CoreV1(), events.NewInMemoryRecorder("test", clock.RealClock{}), operatorSpec, "kcm", "operator", "cpc", "v1", false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pod := resourceread.ReadPodV1OrDie([]byte(podConfigMap.Data["pod.yaml"]))
+	if args := pod.Spec.Containers[0].Args[0]; !strings.Contains(args, " -v=2 --allocate-node-cidrs=false --cert-dir=/var/run/kubernetes --cluster-name=foo ") || !strings.Contains(args, " --kube-api-qps=100 ") {
+		t.Errorf("unexpected kube-controller-manager arguments %q", pod.Spec.Containers[0].Args[0])
+	}
+}
+
+func TestValidateKubeControllerManagerArgs(t *testing.T) {
+	valid := map[string]interface{}{
+		"extendedArguments": map[string]interface{}{
+			"kube-api-qps": []interface{}{"150"},
+			"controllers":  []interface{}{"*", "-ttl"},
+		},
+	}
+	if err := ValidateKubeControllerManagerArgs(valid); err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := map[string]interface{}{
+		"extendedArguments": map[string]interface{}{
+			"kube-api-qps":        []interface{}{"fast"},
+			"no-such-flag":        []interface{}{"true"},
+			"allocate-node-cidrs": []interface{}{"true"},
+		},
+	}
+	expectedError := `invalid kube-controller-manager flags: --kube-api-qps=fast (extendedArguments): invalid float "fast", --no-such-flag (extendedArguments): unknown flag`
+	if err := ValidateKubeControllerManagerArgs(invalid); err == nil || err.Error() != expectedError {
+		t.Fatalf("expected error %q, got %v", expectedError, err)
+	}
+}
@@ -0,0 +1,75 @@
+package targetconfigcontroller
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/configobservation/containerlogging"
+)
+
+// containerLoggingConfig reads and validates the per-container logging config of the operator spec, found at
+// targetconfigcontroller.logging.<container name>. The observedConfig is filled in from the
+// kube-controller-manager-logging configmap in openshift-config, settings in the unsupportedConfigOverrides take
+// precedence over it.
+func containerLoggingConfig(operatorSpec *operatorv1.StaticPodOperatorSpec) (map[string]containerlogging.ContainerLogging, error) {
+	ret := map[string]containerlogging.ContainerLogging{}
+	for _, layer := range [][]byte{operatorSpec.ObservedConfig.Raw, operatorSpec.UnsupportedConfigOverrides.Raw} {
+		layerLogging, err := readContainerLogging(layer)
+		if err != nil {
+			return nil, err
+		}
+		for name, logging := range layerLogging {
+			merged := ret[name]
+			if logging.Verbosity != nil {
+				merged.Verbosity = logging.Verbosity
+			}
+			if len(logging.VModule) > 0 {
+				merged.VModule = logging.VModule
+			}
+			ret[name] = merged
+		}
+	}
+
+	if err := containerlogging.Validate("targetconfigcontroller.logging", ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// readContainerLogging reads targetconfigcontroller.logging of a single config layer, rejecting unknown fields.
+func readContainerLogging(rawConfig []byte) (map[string]containerlogging.ContainerLogging, error) {
+	if len(rawConfig) == 0 {
+		return nil, nil
+	}
+	config := map[string]interface{}{}
+	if err := yaml.Unmarshal(rawConfig, &config); err != nil {
+		return nil, err
+	}
+	loggingConfig, found, err := unstructured.NestedFieldNoCopy(config, "targetconfigcontroller", "logging")
+	if err != nil || !found || loggingConfig == nil {
+		return nil, err
+	}
+
+	loggingJSON, err := json.Marshal(loggingConfig)
+	if err != nil {
+		return nil, err
+	}
+	ret, err := containerlogging.ReadConfig(loggingJSON)
+	if err != nil {
+		return nil, fmt.Errorf("invalid targetconfigcontroller.logging: %w", err)
+	}
+	return ret, nil
+}
+
+// containerVerbosity returns the verbosity of the container, falling back to the one derived from the operator log level.
+func containerVerbosity(logging containerlogging.ContainerLogging, logLevel operatorv1.LogLevel) int {
+	if logging.Verbosity != nil {
+		return *logging.Verbosity
+	}
+	return logLevelToVerbosity(logLevel)
+}
@@ -0,0 +1,184 @@
+package targetconfigcontroller
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/clock"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/configobservation/containerlogging"
+)
+
+func TestContainerLoggingConfig(t *testing.T) {
+	verbosity := func(v int) *int { return &v }
+
+	tests := []struct {
+		name          string
+		observed      string
+		overrides     string
+		expected      map[string]containerlogging.ContainerLogging
+		expectedError string
+	}{
+		{
+			name:     "none",
+			observed: `{}`,
+			expected: map[string]containerlogging.ContainerLogging{},
+		},
+		{
+			name:      "overrides take precedence per field",
+			observed:  `{"targetconfigcontroller": {"logging": {"kube-controller-manager": {"verbosity": 4, "vmodule": "garbagecollector*=6"}}}}`,
+			overrides: `{"targetconfigcontroller": {"logging": {"kube-controller-manager": {"verbosity": 6}, "kube-controller-manager-cert-syncer": {"verbosity": 4}}}}`,
+			expected: map[string]containerlogging.ContainerLogging{
+				"kube-controller-manager":             {Verbosity: verbosity(6), VModule: "garbagecollector*=6"},
+				"kube-controller-manager-cert-syncer": {Verbosity: verbosity(4)},
+			},
+		},
+		{
+			name:          "unknown container",
+			overrides:     `{"targetconfigcontroller": {"logging": {"kcm": {"verbosity": 4}}}}`,
+			expectedError: "targetconfigcontroller.logging.kcm: unknown container",
+		},
+		{
+			name:          "unknown field",
+			overrides:     `{"targetconfigcontroller": {"logging": {"kube-controller-manager": {"v": 4}}}}`,
+			expectedError: `unknown field "v"`,
+		},
+		{
+			name:          "verbosity out of range",
+			overrides:     `{"targetconfigcontroller": {"logging": {"cluster-policy-controller": {"verbosity": 11}}}}`,
+			expectedError: "targetconfigcontroller.logging.cluster-policy-controller.verbosity: must be between 0 and 10, got 11",
+		},
+		{
+			name:          "invalid vmodule",
+			overrides:     `{"targetconfigcontroller": {"logging": {"kube-controller-manager": {"vmodule": "garbagecollector*=6,nodelifecycle"}}}}`,
+			expectedError: `targetconfigcontroller.logging.kube-controller-manager.vmodule: invalid pattern=N setting "nodelifecycle"`,
+		},
+		{
+			name:          "vmodule with a command substitution",
+			overrides:     `{"targetconfigcontroller": {"logging": {"kube-controller-manager": {"vmodule": "$(id)=2"}}}}`,
+			expectedError: `targetconfigcontroller.logging.kube-controller-manager.vmodule: invalid pattern=N setting "$(id)=2"`,
+		},
+		{
+			name:          "vmodule with backticks",
+			overrides:     "{\"targetconfigcontroller\": {\"logging\": {\"kube-controller-manager\": {\"vmodule\": \"`id`=2\"}}}}",
+			expectedError: "targetconfigcontroller.logging.kube-controller-manager.vmodule: invalid pattern=N setting \"`id`=2\"",
+		},
+		{
+			name:          "vmodule with a signed verbosity",
+			overrides:     `{"targetconfigcontroller": {"logging": {"kube-controller-manager": {"vmodule": "garbagecollector*=+6"}}}}`,
+			expectedError: `targetconfigcontroller.logging.kube-controller-manager.vmodule: invalid verbosity "+6" for garbagecollector*, must be between 0 and 10`,
+		},
+		{
+			name:      "vmodule with a path glob",
+			overrides: `{"targetconfigcontroller": {"logging": {"kube-controller-manager": {"vmodule": "pkg/controller/garbage?ollector/*=6"}}}}`,
+			expected: map[string]containerlogging.ContainerLogging{
+				"kube-controller-manager": {VModule: "pkg/controller/garbage?ollector/*=6"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			operatorSpec := &operatorv1.StaticPodOperatorSpec{
+				OperatorSpec: operatorv1.OperatorSpec{
+					ObservedConfig:             runtime.RawExtension{Raw: []byte(test.observed)},
+					UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(test.overrides)},
+				},
+			}
+			actual, err := containerLoggingConfig(operatorSpec)
+			switch {
+			case err != nil && len(test.expectedError) == 0:
+				t.Fatalf("unexpected error: %v", err)
+			case err == nil && len(test.expectedError) != 0:
+				t.Fatalf("expected error %q, got none", test.expectedError)
+			case err != nil && !strings.Contains(err.Error(), test.expectedError):
+				t.Fatalf("expected error %q, got %v", test.expectedError, err)
+			case err != nil:
+				return
+			}
+			if !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestManagePodContainerLogging(t *testing.T) {
+	tests := []struct {
+		name       string
+		overrides  string
+		expected   map[string][]string
+		unexpected map[string][]string
+	}{
+		{
+			name: "operator log level",
+			expected: map[string][]string{
+				"kube-controller-manager":                     {"-v=2"},
+				"cluster-policy-controller":                   {"-v=2"},
+				"kube-controller-manager-recovery-controller": {"-v=2"},
+			},
+			unexpected: map[string][]string{
+				"kube-controller-manager-cert-syncer": {"-v=", "--vmodule"},
+			},
+		},
+		{
+			name: "container logging",
+			overrides: `{"targetconfigcontroller": {"logging": {
+				"kube-controller-manager": {"verbosity": 4, "vmodule": "garbagecollector*=6"},
+				"kube-controller-manager-cert-syncer": {"verbosity": 6}
+			}}}`,
+			expected: map[string][]string{
+				"kube-controller-manager":                     {"-v=4", "--vmodule=garbagecollector*=6"},
+				"cluster-policy-controller":                   {"-v=2"},
+				"kube-controller-manager-recovery-controller": {"-v=2"},
+				"kube-controller-manager-cert-syncer":         {"-v=6"},
+			},
+			unexpected: map[string][]string{
+				"cluster-policy-controller":                   {"--vmodule"},
+				"kube-controller-manager-recovery-controller": {"--vmodule"},
+				"kube-controller-manager-cert-syncer":         {"--vmodule"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			operatorSpec := &operatorv1.StaticPodOperatorSpec{
+				OperatorSpec: operatorv1.OperatorSpec{
+					LogLevel:                   operatorv1.Normal,
+					ObservedConfig:             runtime.RawExtension{Raw: []byte(`{}`)},
+					UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(test.overrides)},
+				},
+			}
+
+			podConfigMap, _, err := managePod(context.TODO(), client.CoreV1(), client.CoreV1(), events.NewInMemoryRecorder("test", clock.RealClock{}), operatorSpec, "kcm", "operator", "cpc", "v1", false, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pod := resourceread.ReadPodV1OrDie([]byte(podConfigMap.Data["pod.yaml"]))
+
+			for _, container := range pod.Spec.Containers {
+				args := strings.Join(container.Args, " ")
+				for _, e := range test.expected[container.Name] {
+					if !strings.Contains(args, e) {
+						t.Errorf("expected %q in the %s arguments %q", e, container.Name, args)
+					}
+				}
+				for _, u := range test.unexpected[container.Name] {
+					if strings.Contains(args, u) {
+						t.Errorf("unexpected %q in the %s arguments %q", u, container.Name, args)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package targetconfigcontroller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	kubecontrolplanev1 "github.com/openshift/api/kubecontrolplane/v1"
+	openshiftcontrolplanev1 "github.com/openshift/api/openshiftcontrolplane/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/resource/resourcemerge"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/bindata"
+)
+
+const (
+	// OverrideTargetKubeControllerManager is used for overrides merged into the KubeControllerManagerConfig.
+	OverrideTargetKubeControllerManager = "kube-controller-manager"
+	// OverrideTargetClusterPolicyController is used for overrides merged into the OpenShiftControllerManagerConfig
+	// of the cluster-policy-controller.
+	OverrideTargetClusterPolicyController = "cluster-policy-controller"
+	// OverrideTargetOperator is used for overrides interpreted by the operator itself.
+	OverrideTargetOperator = "operator"
+)
+
+// operatorOverridePaths are the unsupportedConfigOverrides interpreted by the operator rather than by an operand.
+// The paths are matched case-insensitively, like the json decoding of the legacy service CA key.
+var operatorOverridePaths = []string{
+	"EnableDeprecatedAndRemovedServiceCAKeyUntilNextRelease_ThisMakesClusterImpossibleToUpgrade",
+	"targetconfigcontroller.logging",
+}
+
+// overrideSchemas are the operand configs the unsupportedConfigOverrides are merged into.
+var overrideSchemas = []struct {
+	target string
+	schema runtime.Object
+}{
+	{OverrideTargetKubeControllerManager, &kubecontrolplanev1.KubeControllerManagerConfig{}},
+	{OverrideTargetClusterPolicyController, &openshiftcontrolplanev1.OpenShiftControllerManagerConfig{}},
+}
+
+// OverridesSummary lists the unsupportedConfigOverrides of the operator and where they apply.
+type OverridesSummary struct {
+	// Active are the overrides applied to at least one operand or interpreted by the operator.
+	Active []ActiveOverride `json:"active"`
+	// Ignored are the overrides that are pruned from every operand config.
+	Ignored []IgnoredOverride `json:"ignored"`
+}
+
+// ActiveOverride is a single overridden field.
+type ActiveOverride struct {
+	Path    string      `json:"path"`
+	Value   interface{} `json:"value"`
+	Targets []string    `json:"targets"`
+}
+
+// IgnoredOverride is a single override that has no effect.
+type IgnoredOverride struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// SummarizeUnsupportedConfigOverrides validates every field set in the unsupportedConfigOverrides against the operand
+// config schemas. Fields pruned from all of them, or not matching their type, are ignored.
+func SummarizeUnsupportedConfigOverrides(rawOverrides []byte) (*OverridesSummary, error) {
+	summary := &OverridesSummary{Active: []ActiveOverride{}, Ignored: []IgnoredOverride{}}
+	if len(rawOverrides) == 0 {
+		return summary, nil
+	}
+	overrides := map[string]interface{}{}
+	if err := yaml.Unmarshal(rawOverrides, &overrides); err != nil {
+		return nil, fmt.Errorf("failed to parse unsupportedConfigOverrides: %w", err)
+	}
+
+	for _, leaf := range overrideLeaves(nil, overrides) {
+		path := strings.Join(leaf.path, ".")
+		if isOperatorOverride(path) {
+			summary.Active = append(summary.Active, ActiveOverride{Path: path, Value: leaf.value, Targets: []string{OverrideTargetOperator}})
+			continue
+		}
+
+		targets := []string{}
+		schemaErrs := []string{}
+		for _, s := range overrideSchemas {
+			kept, err := keptBySchema(s.schema, leaf.path, leaf.value)
+			if err != nil {
+				schemaErrs = append(schemaErrs, fmt.Sprintf("%s: %v", s.target, err))
+				continue
+			}
+			if kept {
+				targets = append(targets, s.target)
+			}
+		}
+		switch {
+		case len(schemaErrs) > 0:
+			summary.Ignored = append(summary.Ignored, IgnoredOverride{Path: path, Reason: fmt.Sprintf("invalid value: %s", strings.Join(schemaErrs, "; "))})
+		case len(targets) == 0:
+			summary.Ignored = append(summary.Ignored, IgnoredOverride{Path: path, Reason: "unknown field, pruned from every config"})
+		default:
+			summary.Active = append(summary.Active, ActiveOverride{Path: path, Value: leaf.value, Targets: targets})
+		}
+	}
+
+	sort.Slice(summary.Active, func(i, j int) bool { return summary.Active[i].Path < summary.Active[j].Path })
+	sort.Slice(summary.Ignored, func(i, j int) bool { return summary.Ignored[i].Path < summary.Ignored[j].Path })
+	return summary, nil
+}
+
+type overrideLeaf struct {
+	path  []string
+	value interface{}
+}
+
+// overrideLeaves flattens the overrides into the fields they set. Lists and scalars are leaves.
+func overrideLeaves(path []string, config map[string]interface{}) []overrideLeaf {
+	ret := []overrideLeaf{}
+	for key, value := range config {
+		currPath := append(append([]string{}, path...), key)
+		if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 {
+			ret = append(ret, overrideLeaves(currPath, nested)...)
+			continue
+		}
+		ret = append(ret, overrideLeaf{path: currPath, value: value})
+	}
+	return ret
+}
+
+func isOperatorOverride(path string) bool {
+	for _, operatorPath := range operatorOverridePaths {
+		if strings.EqualFold(path, operatorPath) || strings.HasPrefix(strings.ToLower(path), strings.ToLower(operatorPath)+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// keptBySchema merges a config setting only the given field the same way manageKubeControllerManagerConfig does and
+// reports whether the field survives the pruning.
+func keptBySchema(schema runtime.Object, path []string, value interface{}) (bool, error) {
+	config := map[string]interface{}{}
+	if err := unstructured.SetNestedField(config, runtime.DeepCopyJSONValue(value), path...); err != nil {
+		return false, err
+	}
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return false, err
+	}
+	prunedJSON, err := resourcemerge.MergePrunedProcessConfig(schema, nil, configJSON)
+	if err != nil {
+		return false, err
+	}
+	pruned := map[string]interface{}{}
+	if err := json.Unmarshal(prunedJSON, &pruned); err != nil {
+		return false, err
+	}
+	_, found, err := unstructured.NestedFieldNoCopy(pruned, path...)
+	return found, err
+}
+
+// manageUnsupportedConfigOverridesSummary publishes the summary of the unsupportedConfigOverrides in the operator namespace.
+// The summary is returned even if publishing it failed.
+func manageUnsupportedConfigOverridesSummary(ctx context.Context, client corev1client.ConfigMapsGetter, recorder events.Recorder, operatorSpec *operatorv1.StaticPodOperatorSpec) (*OverridesSummary, error) {
+	summary, err := SummarizeUnsupportedConfigOverrides(operatorSpec.UnsupportedConfigOverrides.Raw)
+	if err != nil {
+		return nil, err
+	}
+	summaryYAML, err := yaml.Marshal(summary)
+	if err != nil {
+		return nil, err
+	}
+
+	configMap := resourceread.ReadConfigMapV1OrDie(bindata.MustAsset("assets/kube-controller-manager/unsupported-config-overrides-cm.yaml"))
+	configMap.Data["summary.yaml"] = string(summaryYAML)
+	_, _, err = resourceapply.ApplyConfigMap(ctx, client, recorder, configMap)
+	return summary, err
+}
+
+// unsupportedConfigOverridesCondition reports ignored overrides. It does not degrade the operator, the overrides are
+// unsupported anyway.
+func unsupportedConfigOverridesCondition(summary *OverridesSummary, err error) operatorv1.OperatorCondition {
+	switch {
+	case summary == nil:
+		return operatorv1.OperatorCondition{
+			Type:    "UnsupportedConfigOverridesValid",
+			Status:  operatorv1.ConditionUnknown,
+			Reason:  "SummaryFailed",
+			Message: err.Error(),
+		}
+	case len(summary.Ignored) > 0:
+		messages := []string{}
+		for _, ignored := range summary.Ignored {
+			messages = append(messages, fmt.Sprintf("%s: %s", ignored.Path, ignored.Reason))
+		}
+		return operatorv1.OperatorCondition{
+			Type:    "UnsupportedConfigOverridesValid",
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "IgnoredOverrides",
+			Message: strings.Join(messages, "\n"),
+		}
+	}
+	return operatorv1.OperatorCondition{
+		Type:   "UnsupportedConfigOverridesValid",
+		Status: operatorv1.ConditionTrue,
+		Reason: "AsExpected",
+	}
+}
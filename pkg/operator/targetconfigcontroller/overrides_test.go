@@ -0,0 +1,81 @@
+package targetconfigcontroller
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+func TestSummarizeUnsupportedConfigOverrides(t *testing.T) {
+	tests := []struct {
+		name            string
+		overrides       string
+		expectedActive  []ActiveOverride
+		expectedIgnored []IgnoredOverride
+	}{
+		{
+			name: "none",
+		},
+		{
+			name: "operand and operator overrides",
+			overrides: `
+extendedArguments:
+  kube-api-qps: ["300"]
+featureGates: ["Foo=true"]
+targetconfigcontroller:
+  logging:
+    kube-controller-manager:
+      verbosity: 4
+enableDeprecatedAndRemovedServiceCAKeyUntilNextRelease_ThisMakesClusterImpossibleToUpgrade: true
+`,
+			expectedActive: []ActiveOverride{
+				{Path: "enableDeprecatedAndRemovedServiceCAKeyUntilNextRelease_ThisMakesClusterImpossibleToUpgrade", Value: true, Targets: []string{OverrideTargetOperator}},
+				{Path: "extendedArguments.kube-api-qps", Value: []interface{}{"300"}, Targets: []string{OverrideTargetKubeControllerManager}},
+				{Path: "featureGates", Value: []interface{}{"Foo=true"}, Targets: []string{OverrideTargetClusterPolicyController}},
+				{Path: "targetconfigcontroller.logging.kube-controller-manager.verbosity", Value: float64(4), Targets: []string{OverrideTargetOperator}},
+			},
+		},
+		{
+			name: "unknown and invalid fields",
+			overrides: `
+extendedArgument:
+  kube-api-qps: ["300"]
+extendedArguments:
+  kube-api-burst: "600"
+`,
+			expectedIgnored: []IgnoredOverride{
+				{Path: "extendedArgument.kube-api-qps", Reason: "unknown field, pruned from every config"},
+				{Path: "extendedArguments.kube-api-burst", Reason: "invalid value: kube-controller-manager: "},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			summary, err := SummarizeUnsupportedConfigOverrides([]byte(test.overrides))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(summary.Active) != 0 || len(test.expectedActive) != 0 {
+				if !reflect.DeepEqual(summary.Active, test.expectedActive) {
+					t.Errorf("expected active overrides %#v, got %#v", test.expectedActive, summary.Active)
+				}
+			}
+			if len(summary.Ignored) != len(test.expectedIgnored) {
+				t.Fatalf("expected ignored overrides %#v, got %#v", test.expectedIgnored, summary.Ignored)
+			}
+			for i := range test.expectedIgnored {
+				if summary.Ignored[i].Path != test.expectedIgnored[i].Path || !strings.HasPrefix(summary.Ignored[i].Reason, test.expectedIgnored[i].Reason) {
+					t.Errorf("expected ignored override %#v, got %#v", test.expectedIgnored[i], summary.Ignored[i])
+				}
+			}
+
+			condition := unsupportedConfigOverridesCondition(summary, nil)
+			if expected := len(test.expectedIgnored) == 0; (condition.Status == operatorv1.ConditionTrue) != expected {
+				t.Errorf("unexpected condition %#v", condition)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package targetconfigcontroller
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// imagePlaceholderEnvVars maps the image placeholders of the assets to the operator env var providing the pull spec.
+var imagePlaceholderEnvVars = map[string]string{
+	"${IMAGE}":                           "IMAGE",
+	"${OPERATOR_IMAGE}":                  "OPERATOR_IMAGE",
+	"${CLUSTER_POLICY_CONTROLLER_IMAGE}": "CLUSTER_POLICY_CONTROLLER_IMAGE",
+	"${TOOLS_IMAGE}":                     "TOOLS_IMAGE",
+}
+
+var (
+	placeholderRegexp = regexp.MustCompile(`\$\{[A-Za-z_][A-Za-z0-9_]*\}`)
+
+	// imageReferenceRegexp is a simplified version of the docker reference grammar: [domain/]path[:tag][@digest]
+	imageReferenceRegexp = regexp.MustCompile(`^` +
+		`(?:(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9])(?:\.(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9]))*(?::[0-9]+)?/)?` +
+		`[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*` +
+		`(?::[\w][\w.-]{0,127})?` +
+		`(?:@[A-Za-z][A-Za-z0-9]*(?:[-_+.][A-Za-z][A-Za-z0-9]*)*:[0-9a-fA-F]{32,})?$`)
+)
+
+// UnresolvedPlaceholdersError is returned when a rendered resource still contains template placeholders or
+// malformed image references, usually because an image env var of the operator is not set.
+type UnresolvedPlaceholdersError struct {
+	// Resource is the rendered resource, e.g. configmap/kube-controller-manager-pod.
+	Resource string
+	// Placeholders are the leftover ${...} tokens.
+	Placeholders []string
+	// InvalidImages are the malformed image references.
+	InvalidImages []string
+}
+
+func (e *UnresolvedPlaceholdersError) Error() string {
+	messages := []string{}
+	if len(e.Placeholders) > 0 {
+		messages = append(messages, fmt.Sprintf("unresolved placeholders %s", strings.Join(e.Placeholders, ", ")))
+	}
+	if len(e.InvalidImages) > 0 {
+		messages = append(messages, fmt.Sprintf("invalid image references %s", strings.Join(e.InvalidImages, ", ")))
+	}
+	if envVars := e.MissingEnvVars(); len(envVars) > 0 {
+		messages = append(messages, fmt.Sprintf("check the operator env vars %s", strings.Join(envVars, ", ")))
+	}
+	return fmt.Sprintf("%s: %s", e.Resource, strings.Join(messages, "; "))
+}
+
+// MissingEnvVars returns the operator env vars that would have resolved the leftover placeholders.
+func (e *UnresolvedPlaceholdersError) MissingEnvVars() []string {
+	envVars := sets.New[string]()
+	for _, placeholder := range e.Placeholders {
+		if envVar, ok := imagePlaceholderEnvVars[placeholder]; ok {
+			envVars.Insert(envVar)
+		}
+	}
+	return sets.List(envVars)
+}
+
+// checkRenderedPod verifies that all images of the pod are valid references and that the containers do not contain
+// placeholders other than references to their own env vars.
+func checkRenderedPod(resource string, pod *corev1.Pod) error {
+	placeholders := sets.New[string]()
+	invalidImages := sets.New[string]()
+	for _, container := range append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+		if leftover := placeholderRegexp.FindAllString(container.Image, -1); len(leftover) > 0 {
+			placeholders.Insert(leftover...)
+		} else if !imageReferenceRegexp.MatchString(container.Image) {
+			invalidImages.Insert(fmt.Sprintf("%q (container %s)", container.Image, container.Name))
+		}
+
+		// ${NAME} in commands, args and env is expanded by the shell or the kubelet when NAME is an env var of the container
+		envVars := sets.New[string]()
+		for _, env := range container.Env {
+			envVars.Insert(env.Name)
+		}
+		fields := append(append([]string{}, container.Command...), container.Args...)
+		for _, env := range container.Env {
+			fields = append(fields, env.Value)
+		}
+		for _, field := range fields {
+			for _, placeholder := range placeholderRegexp.FindAllString(field, -1) {
+				if !envVars.Has(strings.TrimSuffix(strings.TrimPrefix(placeholder, "${"), "}")) {
+					placeholders.Insert(placeholder)
+				}
+			}
+		}
+	}
+
+	if placeholders.Len() > 0 || invalidImages.Len() > 0 {
+		return &UnresolvedPlaceholdersError{Resource: resource, Placeholders: sets.List(placeholders), InvalidImages: sets.List(invalidImages)}
+	}
+	return nil
+}
+
+// checkRenderedConfigMap verifies that the configmap data does not contain placeholders. Pods embedded in the
+// configmap are checked with checkRenderedPod.
+func checkRenderedConfigMap(configMap *corev1.ConfigMap) error {
+	resource := fmt.Sprintf("configmap/%s", configMap.Name)
+	placeholders := sets.New[string]()
+	invalidImages := sets.New[string]()
+	for _, value := range configMap.Data {
+		pod := &corev1.Pod{}
+		if err := yaml.Unmarshal([]byte(value), pod); err == nil && pod.Kind == "Pod" {
+			if err := checkRenderedPod(resource, pod); err != nil {
+				podErr := err.(*UnresolvedPlaceholdersError)
+				placeholders.Insert(podErr.Placeholders...)
+				invalidImages.Insert(podErr.InvalidImages...)
+			}
+			continue
+		}
+		placeholders.Insert(placeholderRegexp.FindAllString(value, -1)...)
+	}
+
+	if placeholders.Len() > 0 || invalidImages.Len() > 0 {
+		return &UnresolvedPlaceholdersError{Resource: resource, Placeholders: sets.List(placeholders), InvalidImages: sets.List(invalidImages)}
+	}
+	return nil
+}
+
+// unresolvedPlaceholders returns the UnresolvedPlaceholdersErrors wrapped in errs.
+func unresolvedPlaceholders(errs []error) []*UnresolvedPlaceholdersError {
+	ret := []*UnresolvedPlaceholdersError{}
+	for _, err := range errs {
+		var placeholdersErr *UnresolvedPlaceholdersError
+		if errors.As(err, &placeholdersErr) {
+			ret = append(ret, placeholdersErr)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,114 @@
+package targetconfigcontroller
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/clock"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/operatorclient"
+)
+
+func TestManagePodUnresolvedPlaceholders(t *testing.T) {
+	tests := []struct {
+		name                  string
+		image                 string
+		operatorImage         string
+		cpcImage              string
+		expectedPlaceholders  []string
+		expectedInvalidImages []string
+		expectedEnvVars       []string
+	}{
+		{
+			name:          "all images set",
+			image:         "quay.io/openshift/hyperkube@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+			operatorImage: "quay.io/openshift/operator:v4.16",
+			cpcImage:      "registry.local:5000/openshift/cpc",
+		},
+		{
+			name:                 "missing image",
+			operatorImage:        "operator",
+			cpcImage:             "cpc",
+			expectedPlaceholders: []string{"${IMAGE}"},
+			expectedEnvVars:      []string{"IMAGE"},
+		},
+		{
+			name:                 "missing operator and cluster-policy-controller images",
+			image:                "kcm",
+			expectedPlaceholders: []string{"${CLUSTER_POLICY_CONTROLLER_IMAGE}", "${OPERATOR_IMAGE}"},
+			expectedEnvVars:      []string{"CLUSTER_POLICY_CONTROLLER_IMAGE", "OPERATOR_IMAGE"},
+		},
+		{
+			name:                  "malformed image",
+			image:                 "Quay.io/Hyperkube:",
+			operatorImage:         "operator",
+			cpcImage:              "cpc",
+			expectedInvalidImages: []string{`"Quay.io/Hyperkube:" (container kube-controller-manager)`},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			operatorSpec := &operatorv1.StaticPodOperatorSpec{
+				OperatorSpec: operatorv1.OperatorSpec{ObservedConfig: runtime.RawExtension{Raw: []byte(`{}`)}},
+			}
+
+			_, _, err := managePod(context.TODO(), client.CoreV1(), client.CoreV1(), events.NewInMemoryRecorder("test", clock.RealClock{}), operatorSpec, test.image, test.operatorImage, test.cpcImage, "v1", false, true)
+			if len(test.expectedPlaceholders) == 0 && len(test.expectedInvalidImages) == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			var placeholdersErr *UnresolvedPlaceholdersError
+			if !errors.As(err, &placeholdersErr) {
+				t.Fatalf("expected *UnresolvedPlaceholdersError, got %v", err)
+			}
+			if !equalStrings(placeholdersErr.Placeholders, test.expectedPlaceholders) {
+				t.Errorf("expected placeholders %v, got %v", test.expectedPlaceholders, placeholdersErr.Placeholders)
+			}
+			if !equalStrings(placeholdersErr.InvalidImages, test.expectedInvalidImages) {
+				t.Errorf("expected invalid images %v, got %v", test.expectedInvalidImages, placeholdersErr.InvalidImages)
+			}
+			if envVars := placeholdersErr.MissingEnvVars(); !equalStrings(envVars, test.expectedEnvVars) {
+				t.Errorf("expected env vars %v, got %v", test.expectedEnvVars, envVars)
+			}
+			if _, err := client.CoreV1().ConfigMaps(operatorclient.TargetNamespace).Get(context.TODO(), "kube-controller-manager-pod", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+				t.Fatalf("expected kube-controller-manager-pod not to be written, got %v", err)
+			}
+		})
+	}
+}
+
+func TestManageRecyclerUnresolvedPlaceholders(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	recorder := events.NewInMemoryRecorder("test", clock.RealClock{})
+
+	_, _, err := manageRecycler(context.TODO(), client.CoreV1(), recorder, "")
+	var placeholdersErr *UnresolvedPlaceholdersError
+	if !errors.As(err, &placeholdersErr) {
+		t.Fatalf("expected *UnresolvedPlaceholdersError, got %v", err)
+	}
+	if expected := "configmap/recycler-config: unresolved placeholders ${TOOLS_IMAGE}; check the operator env vars TOOLS_IMAGE"; err.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, err.Error())
+	}
+
+	if _, _, err := manageRecycler(context.TODO(), client.CoreV1(), recorder, "quay.io/openshift/tools:latest"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	return (len(a) == 0 && len(b) == 0) || reflect.DeepEqual(a, b)
+}
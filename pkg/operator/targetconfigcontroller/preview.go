@@ -0,0 +1,177 @@
+package targetconfigcontroller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/pmezard/go-difflib/difflib"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+	"github.com/openshift/library-go/pkg/operator/revisioncontroller"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/bindata"
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/operatorclient"
+)
+
+// PreviewRevisionsAnnotation on the kubecontrollermanager/cluster resource stops the operator from creating new
+// revisions and from updating the revisioned configmaps it manages. Instead, their diff against the latest revision is
+// written to the kube-controller-manager-revision-preview configmap.
+const PreviewRevisionsAnnotation = "kube-controller-manager.openshift.io/preview-revisions"
+
+// previewRevisionsEnabled returns whether the operator resource asks for a preview instead of new revisions.
+func previewRevisionsEnabled(annotations map[string]string) bool {
+	return strings.EqualFold(annotations[PreviewRevisionsAnnotation], "true")
+}
+
+// NewRevisionPreviewPrecondition holds back new revisions while the preview is requested, so that revisioned resources
+// updated by other controllers (e.g. serving-cert or service-ca) are not rolled out either.
+func NewRevisionPreviewPrecondition(operatorLister cache.GenericLister) revisioncontroller.PreconditionFunc {
+	return func(ctx context.Context) (bool, error) {
+		operator, err := operatorLister.Get("cluster")
+		if err != nil {
+			return false, err
+		}
+		operatorMeta, err := meta.Accessor(operator)
+		if err != nil {
+			return false, err
+		}
+		return !previewRevisionsEnabled(operatorMeta.GetAnnotations()), nil
+	}
+}
+
+// PreviewRevision diffs the rendered revisioned configmaps against the ones of the given revision. Revision 0 is diffed
+// against empty configmaps.
+func PreviewRevision(ctx context.Context, client corev1client.ConfigMapsGetter, revision int32, rendered ...*corev1.ConfigMap) (string, error) {
+	diffs := []string{}
+	for _, required := range rendered {
+		current := &corev1.ConfigMap{}
+		fromName := "/dev/null"
+		if revision > 0 {
+			fromName = fmt.Sprintf("%s-%d", required.Name, revision)
+			existing, err := client.ConfigMaps(required.Namespace).Get(ctx, fromName, metav1.GetOptions{})
+			switch {
+			case apierrors.IsNotFound(err):
+				fromName = "/dev/null"
+			case err != nil:
+				return "", err
+			default:
+				current = existing
+			}
+		}
+
+		keys := map[string]struct{}{}
+		for key := range current.Data {
+			keys[key] = struct{}{}
+		}
+		for key := range required.Data {
+			keys[key] = struct{}{}
+		}
+		sortedKeys := []string{}
+		for key := range keys {
+			sortedKeys = append(sortedKeys, key)
+		}
+		sort.Strings(sortedKeys)
+
+		for _, key := range sortedKeys {
+			diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+				A:        difflib.SplitLines(previewValue(key, current.Data[key])),
+				B:        difflib.SplitLines(previewValue(key, required.Data[key])),
+				FromFile: fmt.Sprintf("%s/%s", fromName, key),
+				ToFile:   fmt.Sprintf("%s/%s", required.Name, key),
+				Context:  3,
+			})
+			if err != nil {
+				return "", err
+			}
+			if len(diff) > 0 {
+				diffs = append(diffs, diff)
+			}
+		}
+	}
+	return strings.Join(diffs, ""), nil
+}
+
+// previewValue renders the json of the config.yaml and pod.yaml keys as yaml, so that the diff is line based.
+func previewValue(key, value string) string {
+	if !strings.HasSuffix(key, ".yaml") || len(value) == 0 {
+		return value
+	}
+	if yamlValue, err := yaml.JSONToYAML([]byte(value)); err == nil {
+		return string(yamlValue)
+	}
+	return value
+}
+
+// revisionedConfigMap renders one of the revisioned configmaps managed by the target config controller. name is used
+// to report errors.
+type revisionedConfigMap struct {
+	name   string
+	render func() (*corev1.ConfigMap, error)
+}
+
+// manageRevisionPreview renders the revisioned configmaps without applying them and publishes their diff against the
+// latest available revision.
+func manageRevisionPreview(ctx context.Context, client corev1client.CoreV1Interface, recorder events.Recorder, latestAvailableRevision int32, configMaps []revisionedConfigMap) error {
+	rendered := []*corev1.ConfigMap{}
+	for _, configMap := range configMaps {
+		required, err := configMap.render()
+		if err != nil {
+			return fmt.Errorf("%q: %w", configMap.name, err)
+		}
+		rendered = append(rendered, required)
+	}
+
+	diff, err := PreviewRevision(ctx, client, latestAvailableRevision, rendered...)
+	if err != nil {
+		return err
+	}
+	configMap := resourceread.ReadConfigMapV1OrDie(bindata.MustAsset("assets/kube-controller-manager/revision-preview-cm.yaml"))
+	configMap.Data["revision"] = fmt.Sprintf("%d", latestAvailableRevision)
+	configMap.Data["diff"] = diff
+	if _, _, err := resourceapply.ApplyConfigMap(ctx, client, recorder, configMap); err != nil {
+		return fmt.Errorf("%q: %w", "configmap/"+configMap.Name, err)
+	}
+	return nil
+}
+
+// removeRevisionPreview deletes the preview once the revisioned configmaps are applied again, it would be stale.
+func removeRevisionPreview(ctx context.Context, lister corev1listers.ConfigMapLister, client corev1client.ConfigMapsGetter, recorder events.Recorder) error {
+	configMap := resourceread.ReadConfigMapV1OrDie(bindata.MustAsset("assets/kube-controller-manager/revision-preview-cm.yaml"))
+	if _, err := lister.ConfigMaps(configMap.Namespace).Get(configMap.Name); apierrors.IsNotFound(err) {
+		return nil
+	}
+	_, _, err := resourceapply.DeleteConfigMap(ctx, client, recorder, configMap)
+	return err
+}
+
+// revisionPreviewCondition reports that new revisions are held back by the preview mode.
+func revisionPreviewCondition(enabled bool) operatorv1.OperatorCondition {
+	if !enabled {
+		return operatorv1.OperatorCondition{
+			Type:   "RevisionPreview",
+			Status: operatorv1.ConditionFalse,
+			Reason: "AsExpected",
+		}
+	}
+	return operatorv1.OperatorCondition{
+		Type:   "RevisionPreview",
+		Status: operatorv1.ConditionTrue,
+		Reason: "PreviewRequested",
+		Message: fmt.Sprintf("new revisions are paused and changes to the revisioned configmaps are not applied, "+
+			"review them in configmap/%s -n %s and remove the %s annotation to roll them out",
+			"kube-controller-manager-revision-preview", operatorclient.OperatorNamespace, PreviewRevisionsAnnotation),
+	}
+}
@@ -0,0 +1,103 @@
+package targetconfigcontroller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestPreviewRevision(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-controller-manager", Name: "config-3"},
+		Data:       map[string]string{"config.yaml": `{"extendedArguments":{"kube-api-burst":["300"],"kube-api-qps":["150"]}}`},
+	})
+	required := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-controller-manager", Name: "config"},
+		Data:       map[string]string{"config.yaml": `{"extendedArguments":{"kube-api-burst":["300"],"kube-api-qps":["300"]}}`},
+	}
+
+	tests := []struct {
+		name     string
+		revision int32
+		expected []string
+	}{
+		{
+			name:     "latest revision",
+			revision: 3,
+			expected: []string{"--- config-3/config.yaml", "+++ config/config.yaml", "-  - \"150\"", "+  - \"300\""},
+		},
+		{
+			name:     "missing revision",
+			revision: 4,
+			expected: []string{"--- /dev/null/config.yaml", "+  kube-api-burst:"},
+		},
+		{
+			name:     "no revision yet",
+			revision: 0,
+			expected: []string{"--- /dev/null/config.yaml", "+  kube-api-qps:"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diff, err := PreviewRevision(context.TODO(), client.CoreV1(), test.revision, required)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, expected := range test.expected {
+				if !strings.Contains(diff, expected) {
+					t.Errorf("expected %q in diff:\n%s", expected, diff)
+				}
+			}
+		})
+	}
+
+	// rendering the same content as the latest revision produces no diff
+	diff, err := PreviewRevision(context.TODO(), client.CoreV1(), 3, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-controller-manager", Name: "config"},
+		Data:       map[string]string{"config.yaml": `{"extendedArguments":{"kube-api-qps":["150"],"kube-api-burst":["300"]}}`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff) > 0 {
+		t.Errorf("expected no diff, got:\n%s", diff)
+	}
+}
+
+func TestRevisionPreviewPrecondition(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		expected    bool
+	}{
+		{name: "no annotation", expected: true},
+		{name: "preview requested", annotations: map[string]string{PreviewRevisionsAnnotation: "true"}},
+		{name: "preview disabled", annotations: map[string]string{PreviewRevisionsAnnotation: "false"}, expected: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			operator := &unstructured.Unstructured{}
+			operator.SetName("cluster")
+			operator.SetAnnotations(test.annotations)
+			if err := indexer.Add(operator); err != nil {
+				t.Fatal(err)
+			}
+			precondition := NewRevisionPreviewPrecondition(cache.NewGenericLister(indexer, schema.GroupResource{Group: "operator.openshift.io", Resource: "kubecontrollermanagers"}))
+			actual, err := precondition(context.TODO())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package targetconfigcontroller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	corev1 "k8s.io/api/core/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/bindata"
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/configobservation/configobservercontroller"
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/operatorclient"
+)
+
+const (
+	// ConfigLayerDefault is the default config shipped with the operator in assets/config/defaultconfig.yaml.
+	ConfigLayerDefault = "default"
+	// ConfigLayerObserved is the observedConfig of the operator. It is qualified with the observer when known.
+	ConfigLayerObserved = "observedConfig"
+	// ConfigLayerOverride is the unsupportedConfigOverrides of the operator.
+	ConfigLayerOverride = "unsupportedConfigOverrides"
+)
+
+// ConfigProvenance explains where every flag of the kube-controller-manager came from.
+type ConfigProvenance struct {
+	Flags []FlagProvenance `json:"flags"`
+}
+
+// FlagProvenance is the effective value of a kube-controller-manager flag and the layer that set it.
+type FlagProvenance struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+	// Layer is the config layer or operator input that set the effective values.
+	Layer string `json:"layer"`
+	// Overridden lists the values of the other config layers that set this flag, lowest layer first.
+	Overridden []LayerValues `json:"overridden,omitempty"`
+}
+
+// LayerValues are the values a config layer set a flag to.
+type LayerValues struct {
+	Layer  string   `json:"layer"`
+	Values []string `json:"values"`
+}
+
+// ExplainKubeControllerManagerConfig computes the provenance of the flags of the kube-controller-manager for the given
+// operator spec.
+func ExplainKubeControllerManagerConfig(ctx context.Context, secretsGetter corev1client.SecretsGetter, operatorSpec *operatorv1.StaticPodOperatorSpec) (*ConfigProvenance, error) {
+	pod := resourceread.ReadPodV1OrDie(bindata.MustAsset("assets/kube-controller-manager/pod.yaml"))
+	kcmArgs, err := kubeControllerManagerArguments(ctx, secretsGetter, operatorclient.TargetNamespace, strings.TrimSpace(pod.Spec.Containers[0].Args[0]), operatorSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	layers, err := extendedArgumentLayers(operatorSpec)
+	if err != nil {
+		return nil, err
+	}
+	return explainArguments(kcmArgs, layers), nil
+}
+
+// layerArguments are the extendedArguments set by one config layer. A nil value means the layer unset the flag.
+type layerArguments struct {
+	name string
+	args map[string][]string
+}
+
+// extendedArgumentLayers returns the extendedArguments of every layer merged by manageKubeControllerManagerConfig,
+// in merge order.
+func extendedArgumentLayers(operatorSpec *operatorv1.StaticPodOperatorSpec) ([]layerArguments, error) {
+	configs := []struct {
+		name   string
+		config []byte
+	}{
+		{ConfigLayerDefault, bindata.MustAsset("assets/config/defaultconfig.yaml")},
+		{ConfigLayerObserved, operatorSpec.ObservedConfig.Raw},
+		{ConfigLayerOverride, operatorSpec.UnsupportedConfigOverrides.Raw},
+	}
+
+	layers := []layerArguments{}
+	for _, c := range configs {
+		layer := layerArguments{name: c.name, args: map[string][]string{}}
+		if len(c.config) > 0 {
+			config := struct {
+				ExtendedArguments map[string][]string `json:"extendedArguments"`
+			}{}
+			if err := yaml.Unmarshal(c.config, &config); err != nil {
+				return nil, fmt.Errorf("failed to read extendedArguments of %s: %w", c.name, err)
+			}
+			layer.args = config.ExtendedArguments
+		}
+		layers = append(layers, layer)
+	}
+	return layers, nil
+}
+
+// explainArguments attributes every flag in args to the layer that set it.
+func explainArguments(args *Arguments, layers []layerArguments) *ConfigProvenance {
+	byName := map[string][]Argument{}
+	names := []string{}
+	for _, arg := range args.List() {
+		if _, ok := byName[arg.Name]; !ok {
+			names = append(names, arg.Name)
+		}
+		byName[arg.Name] = append(byName[arg.Name], arg)
+	}
+
+	provenance := &ConfigProvenance{Flags: []FlagProvenance{}}
+	for _, name := range names {
+		flag := FlagProvenance{Name: name, Layer: argumentSourceLayer(byName[name][0].Source)}
+		for _, arg := range byName[name] {
+			flag.Values = append(flag.Values, arg.Value)
+		}
+
+		setBy := []LayerValues{}
+		for _, layer := range layers {
+			if values, ok := layer.args[name]; ok {
+				setBy = append(setBy, LayerValues{Layer: configLayerName(layer.name, name), Values: values})
+			}
+		}
+		// config layers replace the extendedArguments of the layers below, the last one wins
+		if isConfigSource(byName[name][0].Source) && len(setBy) > 0 {
+			flag.Layer = setBy[len(setBy)-1].Layer
+			setBy = setBy[:len(setBy)-1]
+		}
+		if len(setBy) > 0 {
+			flag.Overridden = setBy
+		}
+		provenance.Flags = append(provenance.Flags, flag)
+	}
+
+	sort.SliceStable(provenance.Flags, func(i, j int) bool { return provenance.Flags[i].Name < provenance.Flags[j].Name })
+	return provenance
+}
+
+func argumentSourceLayer(source ArgumentSource) string {
+	switch source {
+	case ArgumentSourceServingInfo:
+		return configLayerName(ConfigLayerObserved, "servingInfo")
+	case ArgumentSourceLogLevel:
+		return "operator logLevel"
+	}
+	return string(source)
+}
+
+// isConfigSource returns whether source is one of the layers of the kube-controller-manager config.
+func isConfigSource(source ArgumentSource) bool {
+	switch source {
+	case ArgumentSourceConfig, ArgumentSourceDefaultConfig, ArgumentSourceObservedConfig, ArgumentSourceUnsupportedConfigOverrides:
+		return true
+	}
+	return false
+}
+
+func configLayerName(layer, key string) string {
+	if layer != ConfigLayerObserved {
+		return layer
+	}
+	if key == "servingInfo" {
+		return fmt.Sprintf("%s (ObserveTLSSecurityProfile)", layer)
+	}
+	if observer, ok := configobservercontroller.ExtendedArgumentObservers[key]; ok {
+		return fmt.Sprintf("%s (%s)", layer, observer)
+	}
+	return layer
+}
+
+// manageConfigProvenance publishes the provenance of the kube-controller-manager flags in the operator namespace.
+func manageConfigProvenance(ctx context.Context, client corev1client.CoreV1Interface, recorder events.Recorder, operatorSpec *operatorv1.StaticPodOperatorSpec) (*corev1.ConfigMap, bool, error) {
+	provenance, err := ExplainKubeControllerManagerConfig(ctx, client, operatorSpec)
+	if err != nil {
+		return nil, false, err
+	}
+	provenanceYAML, err := yaml.Marshal(provenance)
+	if err != nil {
+		return nil, false, err
+	}
+
+	configMap := resourceread.ReadConfigMapV1OrDie(bindata.MustAsset("assets/kube-controller-manager/config-provenance-cm.yaml"))
+	configMap.Data["provenance.yaml"] = string(provenanceYAML)
+	return resourceapply.ApplyConfigMap(ctx, client, recorder, configMap)
+}
@@ -0,0 +1,90 @@
+package targetconfigcontroller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/clock"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/operatorclient"
+)
+
+func TestExplainKubeControllerManagerConfig(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	recorder := events.NewInMemoryRecorder("test", clock.RealClock{})
+	operatorSpec := &operatorv1.StaticPodOperatorSpec{
+		OperatorSpec: operatorv1.OperatorSpec{
+			LogLevel: operatorv1.Debug,
+			ObservedConfig: runtime.RawExtension{Raw: []byte(`{
+				"extendedArguments": {"cluster-name": ["foo-abcde"], "kube-api-qps": ["200"]},
+				"servingInfo": {"minTLSVersion": "VersionTLS12"}
+			}`)},
+			UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(`{"extendedArguments": {"kube-api-burst": ["400"], "controllers": ["*"]}}`)},
+		},
+	}
+
+	if _, _, err := manageKubeControllerManagerConfig(context.TODO(), client.CoreV1(), recorder, operatorSpec); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := manageConfigProvenance(context.TODO(), client.CoreV1(), recorder, operatorSpec); err != nil {
+		t.Fatal(err)
+	}
+
+	configMap, err := client.CoreV1().ConfigMaps(operatorclient.OperatorNamespace).Get(context.TODO(), "kube-controller-manager-config-provenance", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	provenance := &ConfigProvenance{}
+	if err := yaml.Unmarshal([]byte(configMap.Data["provenance.yaml"]), provenance); err != nil {
+		t.Fatal(err)
+	}
+	flags := map[string]FlagProvenance{}
+	for _, flag := range provenance.Flags {
+		flags[flag.Name] = flag
+	}
+
+	expected := []FlagProvenance{
+		{Name: "kubeconfig", Values: []string{"/etc/kubernetes/static-pod-resources/configmaps/controller-manager-kubeconfig/kubeconfig"}, Layer: "pod.yaml"},
+		{Name: "v", Values: []string{"4"}, Layer: "operator logLevel"},
+		{Name: "tls-min-version", Values: []string{"VersionTLS12"}, Layer: "observedConfig (ObserveTLSSecurityProfile)"},
+		{Name: "cluster-name", Values: []string{"foo-abcde"}, Layer: "observedConfig (ObserveInfraID)"},
+		{Name: "leader-elect", Values: []string{"true"}, Layer: "default"},
+		{
+			Name:   "kube-api-qps",
+			Values: []string{"200"},
+			Layer:  "observedConfig (ObserveClusterSizing)",
+			Overridden: []LayerValues{
+				{Layer: "default", Values: []string{"150"}},
+			},
+		},
+		{
+			Name:   "kube-api-burst",
+			Values: []string{"400"},
+			Layer:  "unsupportedConfigOverrides",
+			Overridden: []LayerValues{
+				{Layer: "default", Values: []string{"300"}},
+			},
+		},
+		{
+			Name:   "controllers",
+			Values: []string{"*"},
+			Layer:  "unsupportedConfigOverrides",
+			Overridden: []LayerValues{
+				{Layer: "default", Values: []string{"*", "-ttl", "-bootstrapsigner", "-tokencleaner", "selinux-warning-controller"}},
+			},
+		},
+	}
+	for _, expectedFlag := range expected {
+		if actual := flags[expectedFlag.Name]; !reflect.DeepEqual(actual, expectedFlag) {
+			t.Errorf("expected %#v, got %#v", expectedFlag, actual)
+		}
+	}
+}
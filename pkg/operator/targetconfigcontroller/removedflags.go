@@ -0,0 +1,137 @@
+package targetconfigcontroller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/apimachinery/pkg/util/version"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/bindata"
+)
+
+// RemovedFlags lists the kube-controller-manager flags and feature gates removed in upcoming Kubernetes versions.
+type RemovedFlags struct {
+	// KubernetesVersion is the major.minor version of the kube-controller-manager shipped in this payload.
+	KubernetesVersion string         `json:"kubernetesVersion"`
+	Flags             []RemovedEntry `json:"flags"`
+	FeatureGates      []RemovedEntry `json:"featureGates"`
+}
+
+// RemovedEntry is a flag or feature gate and the Kubernetes version removing it.
+type RemovedEntry struct {
+	Name         string `json:"name"`
+	DeprecatedIn string `json:"deprecatedIn,omitempty"`
+	RemovedIn    string `json:"removedIn"`
+}
+
+// LoadRemovedFlags reads the table of removed flags embedded in the operator.
+func LoadRemovedFlags() (*RemovedFlags, error) {
+	removed := &RemovedFlags{}
+	if err := yaml.Unmarshal(bindata.MustAsset("assets/config/kube-controller-manager-removed-flags.yaml"), removed); err != nil {
+		return nil, fmt.Errorf("failed to parse the kube-controller-manager removed flags: %w", err)
+	}
+	if _, err := version.ParseGeneric(removed.KubernetesVersion); err != nil {
+		return nil, fmt.Errorf("invalid kubernetesVersion in the kube-controller-manager removed flags: %w", err)
+	}
+	for _, entry := range append(append([]RemovedEntry{}, removed.Flags...), removed.FeatureGates...) {
+		if _, err := version.ParseGeneric(entry.RemovedIn); err != nil {
+			return nil, fmt.Errorf("invalid removedIn of %s in the kube-controller-manager removed flags: %w", entry.Name, err)
+		}
+	}
+	return removed, nil
+}
+
+// RemovedFlagUse is a removed flag or feature gate set by a config layer.
+type RemovedFlagUse struct {
+	// Name is the flag, e.g. --foo, or the feature gate, e.g. feature gate Foo.
+	Name      string
+	Layer     string
+	RemovedIn string
+}
+
+// InUse returns the flags and feature gates of the given config layers that are removed in the next minor version or
+// earlier, sorted by name.
+func (r *RemovedFlags) InUse(layers []layerArguments) []RemovedFlagUse {
+	current := version.MustParseGeneric(r.KubernetesVersion)
+	next := version.MajorMinor(current.Major(), current.Minor()+1)
+	removedSoon := func(entries []RemovedEntry) map[string]string {
+		ret := map[string]string{}
+		for _, entry := range entries {
+			if removedIn := version.MustParseGeneric(entry.RemovedIn); removedIn.LessThan(next) || removedIn.EqualTo(next) {
+				ret[entry.Name] = entry.RemovedIn
+			}
+		}
+		return ret
+	}
+	flags := removedSoon(r.Flags)
+	featureGates := removedSoon(r.FeatureGates)
+
+	uses := []RemovedFlagUse{}
+	for _, layer := range layers {
+		for name, values := range layer.args {
+			if removedIn, ok := flags[name]; ok {
+				uses = append(uses, RemovedFlagUse{Name: "--" + name, Layer: layer.name, RemovedIn: removedIn})
+			}
+			if name != "feature-gates" {
+				continue
+			}
+			for _, value := range values {
+				for _, gate := range strings.Split(value, ",") {
+					gateName, _, _ := strings.Cut(gate, "=")
+					if removedIn, ok := featureGates[gateName]; ok {
+						uses = append(uses, RemovedFlagUse{Name: "feature gate " + gateName, Layer: layer.name, RemovedIn: removedIn})
+					}
+				}
+			}
+		}
+	}
+	sort.Slice(uses, func(i, j int) bool {
+		if uses[i].Name != uses[j].Name {
+			return uses[i].Name < uses[j].Name
+		}
+		return uses[i].Layer < uses[j].Layer
+	})
+	return uses
+}
+
+// removedFlagsInUse returns the removed flags and feature gates set by the default config and the
+// unsupportedConfigOverrides. The observed config follows the cluster and is kept current by its observers.
+func removedFlagsInUse(removed *RemovedFlags, operatorSpec *operatorv1.StaticPodOperatorSpec) ([]RemovedFlagUse, error) {
+	layers, err := extendedArgumentLayers(operatorSpec)
+	if err != nil {
+		return nil, err
+	}
+	checked := []layerArguments{}
+	for _, layer := range layers {
+		if layer.name != ConfigLayerObserved {
+			checked = append(checked, layer)
+		}
+	}
+	return removed.InUse(checked), nil
+}
+
+// removedFlagsCondition blocks upgrades while a config layer uses flags or feature gates the next
+// kube-controller-manager would refuse to start with.
+func removedFlagsCondition(uses []RemovedFlagUse) operatorv1.OperatorCondition {
+	if len(uses) == 0 {
+		return operatorv1.OperatorCondition{
+			Type:   "RemovedFlagsUpgradeable",
+			Status: operatorv1.ConditionTrue,
+			Reason: "AsExpected",
+		}
+	}
+	messages := []string{}
+	for _, use := range uses {
+		messages = append(messages, fmt.Sprintf("%s set in %s is removed in Kubernetes %s", use.Name, use.Layer, use.RemovedIn))
+	}
+	return operatorv1.OperatorCondition{
+		Type:    "RemovedFlagsUpgradeable",
+		Status:  operatorv1.ConditionFalse,
+		Reason:  "RemovedFlagsInUse",
+		Message: strings.Join(messages, "\n"),
+	}
+}
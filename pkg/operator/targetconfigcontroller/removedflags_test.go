@@ -0,0 +1,126 @@
+package targetconfigcontroller
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/version"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+func TestLoadRemovedFlags(t *testing.T) {
+	removed, err := LoadRemovedFlags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed.Flags)+len(removed.FeatureGates) == 0 {
+		t.Fatalf("expected the shipped table to list removals")
+	}
+
+	// the shipped defaults must not block upgrades
+	operatorSpec := &operatorv1.StaticPodOperatorSpec{}
+	uses, err := removedFlagsInUse(removed, operatorSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(uses) > 0 {
+		t.Errorf("expected the default config to use no removed flags, got %#v", uses)
+	}
+
+	// every shipped entry removed in the next minor version blocks upgrades when it is overridden
+	current := version.MustParseGeneric(removed.KubernetesVersion)
+	next := version.MajorMinor(current.Major(), current.Minor()+1)
+	for _, entry := range removed.FeatureGates {
+		if !version.MustParseGeneric(entry.RemovedIn).EqualTo(next) {
+			continue
+		}
+		operatorSpec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(`{"extendedArguments": {"feature-gates": ["` + entry.Name + `=true"]}}`)}
+		uses, err := removedFlagsInUse(removed, operatorSpec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if condition := removedFlagsCondition(uses); condition.Status != operatorv1.ConditionFalse {
+			t.Errorf("expected feature gate %s to block upgrades, got %#v", entry.Name, condition)
+		}
+	}
+	for _, entry := range removed.Flags {
+		if !version.MustParseGeneric(entry.RemovedIn).EqualTo(next) {
+			continue
+		}
+		operatorSpec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(`{"extendedArguments": {"` + entry.Name + `": ["x"]}}`)}
+		uses, err := removedFlagsInUse(removed, operatorSpec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if condition := removedFlagsCondition(uses); condition.Status != operatorv1.ConditionFalse {
+			t.Errorf("expected --%s to block upgrades, got %#v", entry.Name, condition)
+		}
+	}
+}
+
+func TestRemovedFlagsInUse(t *testing.T) {
+	removed := &RemovedFlags{
+		KubernetesVersion: "1.36",
+		Flags: []RemovedEntry{
+			{Name: "kube-api-burst", RemovedIn: "1.37"},
+			{Name: "leader-elect", RemovedIn: "1.37"},
+			{Name: "kube-api-qps", RemovedIn: "1.38"},
+		},
+		FeatureGates: []RemovedEntry{
+			{Name: "OldFeature", DeprecatedIn: "1.35", RemovedIn: "1.37"},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		observed  string
+		overrides string
+		expected  []RemovedFlagUse
+	}{
+		{
+			name:     "defaults",
+			observed: `{"extendedArguments": {"feature-gates": ["OldFeature=true"]}}`,
+			expected: []RemovedFlagUse{
+				{Name: "--kube-api-burst", Layer: ConfigLayerDefault, RemovedIn: "1.37"},
+				{Name: "--leader-elect", Layer: ConfigLayerDefault, RemovedIn: "1.37"},
+			},
+		},
+		{
+			name:      "overrides",
+			overrides: `{"extendedArguments": {"kube-api-burst": ["600"], "kube-api-qps": ["300"], "feature-gates": ["Foo=true,OldFeature=false"]}}`,
+			expected: []RemovedFlagUse{
+				{Name: "--kube-api-burst", Layer: ConfigLayerDefault, RemovedIn: "1.37"},
+				{Name: "--kube-api-burst", Layer: ConfigLayerOverride, RemovedIn: "1.37"},
+				{Name: "--leader-elect", Layer: ConfigLayerDefault, RemovedIn: "1.37"},
+				{Name: "feature gate OldFeature", Layer: ConfigLayerOverride, RemovedIn: "1.37"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			operatorSpec := &operatorv1.StaticPodOperatorSpec{
+				OperatorSpec: operatorv1.OperatorSpec{
+					ObservedConfig:             runtime.RawExtension{Raw: []byte(test.observed)},
+					UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(test.overrides)},
+				},
+			}
+			actual, err := removedFlagsInUse(removed, operatorSpec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, actual)
+			}
+			if condition := removedFlagsCondition(actual); condition.Status != operatorv1.ConditionFalse {
+				t.Errorf("expected Upgradeable=False, got %#v", condition)
+			}
+		})
+	}
+
+	if condition := removedFlagsCondition(nil); condition.Status != operatorv1.ConditionTrue {
+		t.Errorf("expected Upgradeable=True, got %#v", condition)
+	}
+}
@@ -0,0 +1,58 @@
+package targetconfigcontroller
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/configobservation/sizing"
+)
+
+// clusterSizingCondition exposes the sizing tier selected by the ObserveClusterSizing config observer and the flags
+// it tunes.
+func clusterSizingCondition(operatorSpec *operatorv1.StaticPodOperatorSpec) (operatorv1.OperatorCondition, error) {
+	observedConfig := map[string]interface{}{}
+	if len(operatorSpec.ObservedConfig.Raw) > 0 {
+		if err := json.Unmarshal(operatorSpec.ObservedConfig.Raw, &observedConfig); err != nil {
+			return operatorv1.OperatorCondition{}, fmt.Errorf("failed to parse the observed config: %w", err)
+		}
+	}
+	tierName, _, err := unstructured.NestedString(observedConfig, sizing.TierPath...)
+	if err != nil {
+		return operatorv1.OperatorCondition{}, err
+	}
+	if len(tierName) == 0 {
+		return operatorv1.OperatorCondition{
+			Type:    "ClusterSizing",
+			Status:  operatorv1.ConditionUnknown,
+			Reason:  "NotObserved",
+			Message: "no sizing tier has been observed yet",
+		}, nil
+	}
+
+	flags := []string{}
+	for _, tier := range append([]sizing.Tier{sizing.SingleReplicaTier}, sizing.Tiers...) {
+		if tier.Name != tierName {
+			continue
+		}
+		for name, value := range tier.ExtendedArguments {
+			flags = append(flags, fmt.Sprintf("--%s=%s", name, value))
+		}
+	}
+	sort.Strings(flags)
+	message := fmt.Sprintf("kube-controller-manager is tuned for the %s tier", tierName)
+	if len(flags) > 0 {
+		message += ": " + strings.Join(flags, " ")
+	}
+	return operatorv1.OperatorCondition{
+		Type:    "ClusterSizing",
+		Status:  operatorv1.ConditionTrue,
+		Reason:  tierName,
+		Message: message,
+	}, nil
+}
@@ -17,6 +17,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
@@ -44,6 +45,7 @@ import (
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
 
 	"github.com/openshift/cluster-kube-controller-manager-operator/bindata"
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/certmetrics"
 	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/operatorclient"
 	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/version"
 )
@@ -66,6 +68,9 @@ type TargetConfigController struct {
 	configMapLister     corev1listers.ConfigMapLister
 	secretLister        corev1listers.SecretLister
 	infrastuctureLister configv1listers.InfrastructureLister
+
+	// csrSignerLease is shared with the cert-recovery-controllers, the CSR signer is only managed while it is held
+	csrSignerLease *CSRSignerLease
 }
 
 func NewTargetConfigController(
@@ -75,6 +80,7 @@ func NewTargetConfigController(
 	operatorLister cache.GenericLister,
 	kubeClient kubernetes.Interface,
 	infrastuctureInformer configv1informers.InfrastructureInformer,
+	csrSignerLease *CSRSignerLease,
 	eventRecorder events.Recorder,
 ) factory.Controller {
 	c := &TargetConfigController{
@@ -90,6 +96,7 @@ func NewTargetConfigController(
 		operatorClient:      operatorClient,
 		operatorLister:      operatorLister,
 		kubeClient:          kubeClient,
+		csrSignerLease:      csrSignerLease,
 	}
 
 	return factory.New().WithInformers(
@@ -120,7 +127,7 @@ func NewTargetConfigController(
 }
 
 func (c TargetConfigController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
-	operatorSpec, _, _, err := c.operatorClient.GetStaticPodOperatorStateWithQuorum(ctx)
+	operatorSpec, operatorStatus, _, err := c.operatorClient.GetStaticPodOperatorStateWithQuorum(ctx)
 	if err != nil {
 		return err
 	}
@@ -152,11 +159,17 @@ func (c TargetConfigController) sync(ctx context.Context, syncCtx factory.SyncCo
 	// in the case of a new cluster, the first instance ever created will be "good", so there is no possibility to accidentally create a "bad" set of flags.
 	useSecureServiceCA := kcmOperator.Spec.UseMoreSecureServiceCA
 
-	requeue, err := createTargetConfigController(ctx, syncCtx, c, operatorSpec, useSecureServiceCA)
+	previewRevisions := previewRevisionsEnabled(kcmOperator.Annotations)
+
+	requeue, err := createTargetConfigController(ctx, syncCtx, c, operatorSpec, operatorStatus.LatestAvailableRevision, useSecureServiceCA, previewRevisions)
 	if err != nil {
 		return err
 	}
 
+	if err := manageCSRSignerRotation(ctx, c, syncCtx.Recorder(), kcmOperator.Annotations); err != nil {
+		return err
+	}
+
 	if requeue {
 		return factory.SyntheticRequeueError
 	}
@@ -203,47 +216,64 @@ func isRequiredConfigPresent(config []byte) error {
 }
 
 // createTargetConfigController takes care of synchronizing (not upgrading) the thing we're managing.
-func createTargetConfigController(ctx context.Context, syncCtx factory.SyncContext, c TargetConfigController, operatorSpec *operatorv1.StaticPodOperatorSpec, useSecureServiceCA bool) (bool, error) {
+// In preview mode the revisioned configmaps managed here are only diffed against the latest available revision.
+func createTargetConfigController(ctx context.Context, syncCtx factory.SyncContext, c TargetConfigController, operatorSpec *operatorv1.StaticPodOperatorSpec, latestAvailableRevision int32, useSecureServiceCA, previewRevisions bool) (bool, error) {
 	errors := []error{}
 
-	_, _, err := manageKubeControllerManagerConfig(ctx, c.kubeClient.CoreV1(), syncCtx.Recorder(), operatorSpec)
-	if err != nil {
-		errors = append(errors, fmt.Errorf("%q: %w", "configmap", err))
-	}
-	_, _, err = manageClusterPolicyControllerConfig(ctx, c.kubeClient.CoreV1(), syncCtx.Recorder(), operatorSpec)
-	if err != nil {
-		errors = append(errors, fmt.Errorf("%q: %w", "configmap/cluster-policy-controller-config", err))
-	}
-	_, _, err = manageRecycler(ctx, c.kubeClient.CoreV1(), syncCtx.Recorder(), c.toolsImagePullSpec)
-	if err != nil {
-		errors = append(errors, fmt.Errorf("%q: %w", "configmap/recycler-config", err))
-	}
-	_, _, err = ManageCSRIntermediateCABundle(ctx, c.secretLister, c.kubeClient.CoreV1(), syncCtx.Recorder())
-	if err != nil {
-		errors = append(errors, fmt.Errorf("%q: %w", "configmap/csr-intermediate-ca", err))
-	}
-	_, _, err = ManageCSRCABundle(ctx, c.configMapLister, c.kubeClient.CoreV1(), syncCtx.Recorder())
-	if err != nil {
-		errors = append(errors, fmt.Errorf("%q: %w", "configmap/csr-controller-ca", err))
+	var err error
+	if !previewRevisions {
+		_, _, err = manageKubeControllerManagerConfig(ctx, c.kubeClient.CoreV1(), syncCtx.Recorder(), operatorSpec)
+		if err != nil {
+			errors = append(errors, fmt.Errorf("%q: %w", "configmap", err))
+		}
+		_, _, err = manageClusterPolicyControllerConfig(ctx, c.kubeClient.CoreV1(), syncCtx.Recorder(), operatorSpec)
+		if err != nil {
+			errors = append(errors, fmt.Errorf("%q: %w", "configmap/cluster-policy-controller-config", err))
+		}
+		_, _, err = manageRecycler(ctx, c.kubeClient.CoreV1(), syncCtx.Recorder(), c.toolsImagePullSpec)
+		if err != nil {
+			errors = append(errors, fmt.Errorf("%q: %w", "configmap/recycler-config", err))
+		}
 	}
-	_, requeueDelay, _, err := ManageCSRSigner(ctx, c.secretLister, c.kubeClient.CoreV1(), syncCtx.Recorder())
-	if err != nil {
-		errors = append(errors, fmt.Errorf("%q: %w", "secrets/csr-signer", err))
+	if signingDuration, err := clusterSigningDuration(operatorSpec); err != nil {
+		errors = append(errors, fmt.Errorf("%q: %w", "cluster-signing-duration", err))
+	} else if _, _, err := ManageUserCSRSigner(ctx, c.secretLister, c.kubeClient.CoreV1(), syncCtx.Recorder(), signingDuration); err != nil {
+		errors = append(errors, fmt.Errorf("%q: %w", "secrets/csr-signer-user", err))
 	}
-	if requeueDelay > 0 {
-		syncCtx.Queue().AddAfter(syncCtx.QueueKey(), requeueDelay)
+	// a cert-recovery-controller holding the csr-signer lease manages the CSR signer in the meantime
+	if c.csrSignerLease == nil || c.csrSignerLease.IsLeader() {
+		_, _, err = ManageCSRIntermediateCABundle(ctx, c.secretLister, c.kubeClient.CoreV1(), syncCtx.Recorder())
+		if err != nil {
+			errors = append(errors, fmt.Errorf("%q: %w", "configmap/csr-intermediate-ca", err))
+		}
+		_, _, err = ManageCSRCABundle(ctx, c.configMapLister, c.kubeClient.CoreV1(), syncCtx.Recorder())
+		if err != nil {
+			errors = append(errors, fmt.Errorf("%q: %w", "configmap/csr-controller-ca", err))
+		}
+		_, requeueDelay, _, err := ManageCSRSigner(ctx, c.secretLister, c.kubeClient.CoreV1(), syncCtx.Recorder())
+		if err != nil {
+			errors = append(errors, fmt.Errorf("%q: %w", "secrets/csr-signer", err))
+		}
+		certmetrics.SetCSRSignerPromotionDelay(requeueDelay)
+		if requeueDelay > 0 {
+			syncCtx.Queue().AddAfter(syncCtx.QueueKey(), requeueDelay)
+		}
 	}
-	_, _, err = manageServiceAccountCABundle(ctx, c.configMapLister, c.kubeClient.CoreV1(), syncCtx.Recorder())
-	if err != nil {
-		errors = append(errors, fmt.Errorf("%q: %w", "configmap/serviceaccount-ca", err))
+	if !previewRevisions {
+		_, _, err = manageServiceAccountCABundle(ctx, c.configMapLister, c.kubeClient.CoreV1(), syncCtx.Recorder())
+		if err != nil {
+			errors = append(errors, fmt.Errorf("%q: %w", "configmap/serviceaccount-ca", err))
+		}
 	}
 	err = ensureLocalhostRecoverySAToken(ctx, c.kubeClient.CoreV1(), syncCtx.Recorder())
 	if err != nil {
 		errors = append(errors, fmt.Errorf("%q: %w", "serviceaccount/localhost-recovery-client", err))
 	}
-	_, _, err = manageControllerManagerKubeconfig(ctx, c.kubeClient.CoreV1(), c.infrastuctureLister, syncCtx.Recorder())
-	if err != nil {
-		errors = append(errors, fmt.Errorf("%q: %w", "configmap/controller-manager-kubeconfig", err))
+	if !previewRevisions {
+		_, _, err = manageControllerManagerKubeconfig(ctx, c.kubeClient.CoreV1(), c.infrastuctureLister, syncCtx.Recorder())
+		if err != nil {
+			errors = append(errors, fmt.Errorf("%q: %w", "configmap/controller-manager-kubeconfig", err))
+		}
 	}
 
 	// Allow the addition of the service ca to token secrets to be enabled by setting an
@@ -264,9 +294,47 @@ func createTargetConfigController(ctx context.Context, syncCtx factory.SyncConte
 		}
 	}
 
-	_, _, err = managePod(ctx, c.kubeClient.CoreV1(), c.kubeClient.CoreV1(), syncCtx.Recorder(), operatorSpec, c.targetImagePullSpec, c.operatorImagePullSpec, c.clusterPolicyControllerPullSpec, c.operatorImageVersion, addServingServiceCAToTokenSecrets, useSecureServiceCA)
+	if previewRevisions {
+		err = manageRevisionPreview(ctx, c.kubeClient.CoreV1(), syncCtx.Recorder(), latestAvailableRevision, []revisionedConfigMap{
+			{"configmap", func() (*corev1.ConfigMap, error) {
+				return renderKubeControllerManagerConfig(operatorSpec)
+			}},
+			{"configmap/cluster-policy-controller-config", func() (*corev1.ConfigMap, error) {
+				return renderClusterPolicyControllerConfig(ctx, c.kubeClient.CoreV1(), operatorSpec)
+			}},
+			{"configmap/recycler-config", func() (*corev1.ConfigMap, error) {
+				return renderRecycler(c.toolsImagePullSpec)
+			}},
+			{"configmap/serviceaccount-ca", func() (*corev1.ConfigMap, error) {
+				_, required, _, err := renderServiceAccountCABundle(c.configMapLister)
+				return required, err
+			}},
+			{"configmap/controller-manager-kubeconfig", func() (*corev1.ConfigMap, error) {
+				return renderControllerManagerKubeconfig(c.infrastuctureLister)
+			}},
+			{"configmap/kube-controller-manager-pod", func() (*corev1.ConfigMap, error) {
+				return renderPod(ctx, c.kubeClient.CoreV1(), operatorSpec, c.targetImagePullSpec, c.operatorImagePullSpec, c.clusterPolicyControllerPullSpec, c.operatorImageVersion, addServingServiceCAToTokenSecrets, useSecureServiceCA)
+			}},
+		})
+		if err != nil {
+			errors = append(errors, err)
+		}
+	} else {
+		_, _, err = managePod(ctx, c.kubeClient.CoreV1(), c.kubeClient.CoreV1(), syncCtx.Recorder(), operatorSpec, c.targetImagePullSpec, c.operatorImagePullSpec, c.clusterPolicyControllerPullSpec, c.operatorImageVersion, addServingServiceCAToTokenSecrets, useSecureServiceCA)
+		if err != nil {
+			errors = append(errors, fmt.Errorf("%q: %w", "configmap/kube-controller-manager-pod", err))
+		}
+		if err := removeRevisionPreview(ctx, c.configMapLister, c.kubeClient.CoreV1(), syncCtx.Recorder()); err != nil {
+			errors = append(errors, fmt.Errorf("%q: %w", "configmap/kube-controller-manager-revision-preview", err))
+		}
+	}
+	if _, _, err := v1helpers.UpdateStaticPodStatus(ctx, c.operatorClient, v1helpers.UpdateStaticPodConditionFn(revisionPreviewCondition(previewRevisions))); err != nil {
+		return true, err
+	}
+
+	_, _, err = manageConfigProvenance(ctx, c.kubeClient.CoreV1(), syncCtx.Recorder(), operatorSpec)
 	if err != nil {
-		errors = append(errors, fmt.Errorf("%q: %w", "configmap/kube-controller-manager-pod", err))
+		errors = append(errors, fmt.Errorf("%q: %w", "configmap/kube-controller-manager-config-provenance", err))
 	}
 
 	err = ensureKubeControllerManagerTrustedCA(ctx, c.kubeClient.CoreV1(), syncCtx.Recorder())
@@ -297,6 +365,36 @@ func createTargetConfigController(ctx context.Context, syncCtx factory.SyncConte
 		return true, err
 	}
 
+	// Upgrades are blocked while the config uses flags the next kube-controller-manager does not know anymore.
+	var removedFlagUses []RemovedFlagUse
+	removedFlags, err := LoadRemovedFlags()
+	if err == nil {
+		removedFlagUses, err = removedFlagsInUse(removedFlags, operatorSpec)
+	}
+	if err != nil {
+		errors = append(errors, fmt.Errorf("removed flags: %w", err))
+	} else if _, _, err := v1helpers.UpdateStaticPodStatus(ctx, c.operatorClient, v1helpers.UpdateStaticPodConditionFn(removedFlagsCondition(removedFlagUses))); err != nil {
+		return true, err
+	}
+
+	overridesSummary, err := manageUnsupportedConfigOverridesSummary(ctx, c.kubeClient.CoreV1(), syncCtx.Recorder(), operatorSpec)
+	if err != nil {
+		errors = append(errors, fmt.Errorf("%q: %w", "configmap/unsupported-config-overrides-summary", err))
+	}
+	if _, _, err := v1helpers.UpdateStaticPodStatus(ctx, c.operatorClient, v1helpers.UpdateStaticPodConditionFn(unsupportedConfigOverridesCondition(overridesSummary, err))); err != nil {
+		return true, err
+	}
+
+	if sizingCondition, err := clusterSizingCondition(operatorSpec); err != nil {
+		errors = append(errors, fmt.Errorf("cluster sizing: %w", err))
+	} else if _, _, err := v1helpers.UpdateStaticPodStatus(ctx, c.operatorClient, v1helpers.UpdateStaticPodConditionFn(sizingCondition)); err != nil {
+		return true, err
+	}
+
+	if err := updateUnresolvedPlaceholdersCondition(ctx, c, syncCtx.Recorder(), unresolvedPlaceholders(errors)); err != nil {
+		return true, err
+	}
+
 	if len(errors) > 0 {
 		condition := operatorv1.OperatorCondition{
 			Type:    "TargetConfigControllerDegraded",
@@ -325,6 +423,44 @@ func createTargetConfigController(ctx context.Context, syncCtx factory.SyncConte
 	return false, nil
 }
 
+// updateUnresolvedPlaceholdersCondition reports rendered resources with unresolved placeholders. A warning event names
+// the missing operator env vars whenever the condition message changes.
+func updateUnresolvedPlaceholdersCondition(ctx context.Context, c TargetConfigController, recorder events.Recorder, placeholderErrs []*UnresolvedPlaceholdersError) error {
+	condition := operatorv1.OperatorCondition{
+		Type:   "UnresolvedPlaceholdersDegraded",
+		Status: operatorv1.ConditionFalse,
+	}
+	if len(placeholderErrs) > 0 {
+		envVars := sets.New[string]()
+		messages := []string{}
+		for _, err := range placeholderErrs {
+			envVars.Insert(err.MissingEnvVars()...)
+			messages = append(messages, err.Error())
+		}
+		condition.Status = operatorv1.ConditionTrue
+		condition.Reason = "InvalidImageReference"
+		if envVars.Len() > 0 {
+			condition.Reason = "MissingImagePullSpec"
+		}
+		condition.Message = strings.Join(messages, "\n")
+
+		_, status, _, err := c.operatorClient.GetStaticPodOperatorState()
+		if err != nil {
+			return err
+		}
+		if existing := v1helpers.FindOperatorCondition(status.Conditions, condition.Type); existing == nil || existing.Message != condition.Message {
+			if envVars.Len() > 0 {
+				recorder.Warningf("UnresolvedPlaceholders", "No revision is produced until the operator env vars %s are set: %s", strings.Join(sets.List(envVars), ", "), condition.Message)
+			} else {
+				recorder.Warningf("UnresolvedPlaceholders", "No revision is produced until the image references are fixed: %s", condition.Message)
+			}
+		}
+	}
+
+	_, _, err := v1helpers.UpdateStaticPodStatus(ctx, c.operatorClient, v1helpers.UpdateStaticPodConditionFn(condition))
+	return err
+}
+
 // clearCloudControllerOwnerCondition removes the CloudControllerOwner condition if it exists.
 // Prior to version 4.15 of OpenShift, this condition was used to signal the ownership of the
 // cloud controllers. After 4.15 this condition is no longer needed as the external cloud
@@ -356,6 +492,18 @@ func removeCloudControllerOwnerCondition(ctx context.Context, c TargetConfigCont
 }
 
 func manageKubeControllerManagerConfig(ctx context.Context, client corev1client.ConfigMapsGetter, recorder events.Recorder, operatorSpec *operatorv1.StaticPodOperatorSpec) (*corev1.ConfigMap, bool, error) {
+	requiredConfigMap, err := renderKubeControllerManagerConfig(operatorSpec)
+	if err != nil {
+		return nil, false, err
+	}
+	return resourceapply.ApplyConfigMap(ctx, client, recorder, requiredConfigMap)
+}
+
+func renderKubeControllerManagerConfig(operatorSpec *operatorv1.StaticPodOperatorSpec) (*corev1.ConfigMap, error) {
+	// the merge below lets the unsupportedConfigOverrides win, do not roll out a config the pod is not rendered for
+	if err := addConfigExtendedArguments(NewArguments(), operatorSpec); err != nil {
+		return nil, fmt.Errorf("invalid kube-controller-manager arguments: %w", err)
+	}
 	configMap := resourceread.ReadConfigMapV1OrDie(bindata.MustAsset("assets/kube-controller-manager/cm.yaml"))
 	defaultConfig := bindata.MustAsset("assets/config/defaultconfig.yaml")
 	requiredConfigMap, _, err := resourcemerge.MergePrunedConfigMap(
@@ -366,13 +514,18 @@ func manageKubeControllerManagerConfig(ctx context.Context, client corev1client.
 		defaultConfig,
 		operatorSpec.ObservedConfig.Raw,
 		operatorSpec.UnsupportedConfigOverrides.Raw)
+	return requiredConfigMap, err
+}
+
+func manageClusterPolicyControllerConfig(ctx context.Context, client corev1client.CoreV1Interface, recorder events.Recorder, operatorSpec *operatorv1.StaticPodOperatorSpec) (*corev1.ConfigMap, bool, error) {
+	requiredConfigMap, err := renderClusterPolicyControllerConfig(ctx, client, operatorSpec)
 	if err != nil {
 		return nil, false, err
 	}
 	return resourceapply.ApplyConfigMap(ctx, client, recorder, requiredConfigMap)
 }
 
-func manageClusterPolicyControllerConfig(ctx context.Context, client corev1client.CoreV1Interface, recorder events.Recorder, operatorSpec *operatorv1.StaticPodOperatorSpec) (*corev1.ConfigMap, bool, error) {
+func renderClusterPolicyControllerConfig(ctx context.Context, client corev1client.SecretsGetter, operatorSpec *operatorv1.StaticPodOperatorSpec) (*corev1.ConfigMap, error) {
 	configMap := resourceread.ReadConfigMapV1OrDie(bindata.MustAsset("assets/kube-controller-manager/cluster-policy-controller-cm.yaml"))
 	defaultConfig := bindata.MustAsset("assets/config/default-cluster-policy-controller-config.yaml")
 	kcmService := resourceread.ReadServiceV1OrDie(bindata.MustAsset("assets/kube-controller-manager/svc.yaml"))
@@ -387,13 +540,13 @@ func manageClusterPolicyControllerConfig(ctx context.Context, client corev1clien
 	}
 
 	if len(servingCertName) == 0 {
-		return nil, false, fmt.Errorf("missing %s annotation in %s/%s service", kcmService.Namespace, kcmService.Name, ServingCertSecretAnnotation)
+		return nil, fmt.Errorf("missing %s annotation in %s/%s service", kcmService.Namespace, kcmService.Name, ServingCertSecretAnnotation)
 	}
 
 	_, err := client.Secrets(operatorclient.TargetNamespace).Get(ctx, servingCertName, metav1.GetOptions{})
 
 	if err != nil && !apierrors.IsNotFound(err) {
-		return nil, false, err
+		return nil, err
 	} else if apierrors.IsNotFound(err) {
 		// Should only apply when starting the cluster so cluster-policy-controller is able to annotate openshift-service-ca namespace.
 		// Then service-ca controller should start and create serving-cert.
@@ -413,10 +566,7 @@ func manageClusterPolicyControllerConfig(ctx context.Context, client corev1clien
 		"config.yaml",
 		nil,
 		configYamls...)
-	if err != nil {
-		return nil, false, err
-	}
-	return resourceapply.ApplyConfigMap(ctx, client, recorder, requiredConfigMap)
+	return requiredConfigMap, err
 }
 
 func ensureLocalhostRecoverySAToken(ctx context.Context, client corev1client.CoreV1Interface, recorder events.Recorder) error {
@@ -469,15 +619,23 @@ func ensureLocalhostRecoverySAToken(ctx context.Context, client corev1client.Cor
 }
 
 func manageControllerManagerKubeconfig(ctx context.Context, client corev1client.CoreV1Interface, infrastructureLister configv1listers.InfrastructureLister, recorder events.Recorder) (*corev1.ConfigMap, bool, error) {
+	requiredCM, err := renderControllerManagerKubeconfig(infrastructureLister)
+	if err != nil {
+		return nil, false, err
+	}
+	return resourceapply.ApplyConfigMap(ctx, client, recorder, requiredCM)
+}
+
+func renderControllerManagerKubeconfig(infrastructureLister configv1listers.InfrastructureLister) (*corev1.ConfigMap, error) {
 	cmString := string(bindata.MustAsset("assets/kube-controller-manager/kubeconfig-cm.yaml"))
 
 	infrastructure, err := infrastructureLister.Get("cluster")
 	if err != nil {
-		return nil, false, err
+		return nil, err
 	}
 	apiServerInternalURL := infrastructure.Status.APIServerInternalURL
 	if len(apiServerInternalURL) == 0 {
-		return nil, false, fmt.Errorf("infrastucture/cluster: missing APIServerInternalURL")
+		return nil, fmt.Errorf("infrastucture/cluster: missing APIServerInternalURL")
 	}
 
 	for pattern, value := range map[string]string{
@@ -486,138 +644,121 @@ func manageControllerManagerKubeconfig(ctx context.Context, client corev1client.
 		cmString = strings.ReplaceAll(cmString, pattern, value)
 	}
 
-	requiredCM := resourceread.ReadConfigMapV1OrDie([]byte(cmString))
-	return resourceapply.ApplyConfigMap(ctx, client, recorder, requiredCM)
+	return resourceread.ReadConfigMapV1OrDie([]byte(cmString)), nil
 }
 
 // manageRecycler applies a ConfigMap containing the recycler config.
 // Owned by storage team/fbertina@redhat.com.
 func manageRecycler(ctx context.Context, configMapsGetter corev1client.ConfigMapsGetter, recorder events.Recorder, imagePullSpec string) (*corev1.ConfigMap, bool, error) {
+	requiredCM, err := renderRecycler(imagePullSpec)
+	if err != nil {
+		return nil, false, err
+	}
+	return resourceapply.ApplyConfigMap(ctx, configMapsGetter, recorder, requiredCM)
+}
+
+func renderRecycler(imagePullSpec string) (*corev1.ConfigMap, error) {
 	cmString := string(bindata.MustAsset("assets/kube-controller-manager/recycler-cm.yaml"))
-	for pattern, value := range map[string]string{
-		"${TOOLS_IMAGE}": imagePullSpec,
-	} {
-		cmString = strings.ReplaceAll(cmString, pattern, value)
+	if len(imagePullSpec) > 0 {
+		cmString = strings.ReplaceAll(cmString, "${TOOLS_IMAGE}", imagePullSpec)
 	}
 	requiredCM := resourceread.ReadConfigMapV1OrDie([]byte(cmString))
-	return resourceapply.ApplyConfigMap(ctx, configMapsGetter, recorder, requiredCM)
+	if err := checkRenderedConfigMap(requiredCM); err != nil {
+		return nil, err
+	}
+	return requiredCM, nil
 }
 
 func managePod(ctx context.Context, configMapsGetter corev1client.ConfigMapsGetter, secretsGetter corev1client.SecretsGetter, recorder events.Recorder, operatorSpec *operatorv1.StaticPodOperatorSpec, imagePullSpec, operatorImagePullSpec, clusterPolicyControllerPullSpec, operatorImageVersion string, addServingServiceCAToTokenSecrets, useSecureServiceCA bool) (*corev1.ConfigMap, bool, error) {
+	configMap, err := renderPod(ctx, secretsGetter, operatorSpec, imagePullSpec, operatorImagePullSpec, clusterPolicyControllerPullSpec, operatorImageVersion, addServingServiceCAToTokenSecrets, useSecureServiceCA)
+	if err != nil {
+		return nil, false, err
+	}
+	return resourceapply.ApplyConfigMap(ctx, configMapsGetter, recorder, configMap)
+}
+
+// renderPod renders the kube-controller-manager-pod configmap.
+func renderPod(ctx context.Context, secretsGetter corev1client.SecretsGetter, operatorSpec *operatorv1.StaticPodOperatorSpec, imagePullSpec, operatorImagePullSpec, clusterPolicyControllerPullSpec, operatorImageVersion string, addServingServiceCAToTokenSecrets, useSecureServiceCA bool) (*corev1.ConfigMap, error) {
 	required := resourceread.ReadPodV1OrDie(bindata.MustAsset("assets/kube-controller-manager/pod.yaml"))
-	// TODO: If the image pull spec is not specified, the "${IMAGE}" will be used as value and the pod will fail to start.
+	// placeholders of missing pull specs are left in place and reported by checkRenderedConfigMap
 	images := map[string]string{
 		"${IMAGE}":                           imagePullSpec,
 		"${OPERATOR_IMAGE}":                  operatorImagePullSpec,
 		"${CLUSTER_POLICY_CONTROLLER_IMAGE}": clusterPolicyControllerPullSpec,
 	}
-	if len(imagePullSpec) > 0 {
-		for i := range required.Spec.Containers {
-			for pat, img := range images {
-				if required.Spec.Containers[i].Image == pat {
-					required.Spec.Containers[i].Image = img
-					break
-				}
-			}
+	for i := range required.Spec.Containers {
+		if img := images[required.Spec.Containers[i].Image]; len(img) > 0 {
+			required.Spec.Containers[i].Image = img
 		}
-		for i := range required.Spec.InitContainers {
-			for pat, img := range images {
-				if required.Spec.InitContainers[i].Image == pat {
-					required.Spec.InitContainers[i].Image = img
-					break
-				}
-			}
+	}
+	for i := range required.Spec.InitContainers {
+		if img := images[required.Spec.InitContainers[i].Image]; len(img) > 0 {
+			required.Spec.InitContainers[i].Image = img
 		}
 	}
 
-	// This section sets the log levels for all containers that take a "1-line" argument
-	logLevel := 2
-	switch operatorSpec.LogLevel {
-	case operatorv1.Normal:
-		logLevel = 2
-	case operatorv1.Debug:
-		logLevel = 4
-	case operatorv1.Trace:
-		logLevel = 6
-	case operatorv1.TraceAll:
-		logLevel = 10
-	default:
-		logLevel = 2
+	logging, err := containerLoggingConfig(operatorSpec)
+	if err != nil {
+		return nil, err
 	}
+	// This section sets the log levels for all containers but the kube-controller-manager
 	// containers[0] = kube-controller-manager
 	// containers[1] = cluster-policy-controller
 	// containers[2] = kube-controller-manager-cert-syncer
 	// containers[3] = kube-controller-manager-recovery-controller
-	containerNames := sets.NewString("kube-controller-manager", "cluster-policy-controller", "kube-controller-manager-recovery-controller")
-	for i := 0; i < len(required.Spec.Containers); i++ {
-		if !containerNames.Has(required.Spec.Containers[i].Name) {
-			continue
-		}
-		containerArgsWithLoglevel := required.Spec.Containers[i].Args
-		if argsCount := len(containerArgsWithLoglevel); argsCount > 1 {
-			return nil, false, fmt.Errorf("expected only one container argument, got %d", argsCount)
+	for i := range required.Spec.Containers {
+		container := &required.Spec.Containers[i]
+		containerLogging := logging[container.Name]
+		var vmoduleArgs []string
+		if len(containerLogging.VModule) > 0 {
+			vmoduleArgs = append(vmoduleArgs, fmt.Sprintf("--vmodule=%s", containerLogging.VModule))
 		}
-		containerArgsWithLoglevel[0] = strings.TrimSpace(containerArgsWithLoglevel[0])
-		containerArgsWithLoglevel[0] += fmt.Sprintf(" -v=%d", logLevel)
-	}
-
-	// now we are only handling args for the main KCM container
-	kcmContainerArgsWithLoglevel := required.Spec.Containers[0].Args
-	if !strings.Contains(kcmContainerArgsWithLoglevel[0], "exec hyperkube kube-controller-manager") {
-		return nil, false, fmt.Errorf("exec hyperkube kube-controller-manager not found in first argument %q", kcmContainerArgsWithLoglevel[0])
-	}
-
-	kcmContainerArgsWithLoglevel[0] = strings.TrimSpace(kcmContainerArgsWithLoglevel[0])
-
-	if _, err := secretsGetter.Secrets(required.Namespace).Get(ctx, "serving-cert", metav1.GetOptions{}); err != nil && !apierrors.IsNotFound(err) {
-		return nil, false, err
-	} else if err == nil {
-		kcmContainerArgsWithLoglevel[0] += " --tls-cert-file=/etc/kubernetes/static-pod-resources/secrets/serving-cert/tls.crt"
-		kcmContainerArgsWithLoglevel[0] += " --tls-private-key-file=/etc/kubernetes/static-pod-resources/secrets/serving-cert/tls.key"
-	}
 
-	kubeControllerManagerConfigMap, err := configMapsGetter.ConfigMaps(required.Namespace).Get(ctx, "config", metav1.GetOptions{})
-	if err != nil && !apierrors.IsNotFound(err) {
-		return nil, false, err
-	}
-	if kubeControllerManagerConfigMap != nil {
-		var kubeControllerManagerConfig map[string]interface{}
-		if err := yaml.Unmarshal([]byte(kubeControllerManagerConfigMap.Data["config.yaml"]), &kubeControllerManagerConfig); err != nil {
-			return nil, false, fmt.Errorf("failed to unmarshal the kube-controller-manager config: %w", err)
-		}
-		if extendedArguments := GetKubeControllerManagerArgs(kubeControllerManagerConfig); len(extendedArguments) > 0 {
-			kcmContainerArgsWithLoglevel[0] += " " + strings.Join(extendedArguments, " ")
+		switch container.Name {
+		case "cluster-policy-controller", "kube-controller-manager-recovery-controller":
+			// these take a "1-line" argument
+			if argsCount := len(container.Args); argsCount > 1 {
+				return nil, fmt.Errorf("expected only one container argument, got %d", argsCount)
+			}
+			loggingArgs := append([]string{fmt.Sprintf("-v=%d", containerVerbosity(containerLogging, operatorSpec.LogLevel))}, vmoduleArgs...)
+			container.Args[0] = strings.Join(append([]string{strings.TrimSpace(container.Args[0])}, loggingArgs...), " ")
+		case "kube-controller-manager-cert-syncer":
+			// the cert-syncer keeps its default verbosity unless one is configured for it
+			if containerLogging.Verbosity != nil {
+				container.Args = append(container.Args, fmt.Sprintf("-v=%d", *containerLogging.Verbosity))
+			}
+			container.Args = append(container.Args, vmoduleArgs...)
 		}
 	}
 
-	var observedConfig map[string]interface{}
-	if err := yaml.Unmarshal(operatorSpec.ObservedConfig.Raw, &observedConfig); err != nil {
-		return nil, false, fmt.Errorf("failed to unmarshal the observedConfig: %w", err)
+	// now we are only handling args for the main KCM container
+	kcmContainerArgs := required.Spec.Containers[0].Args
+	if argsCount := len(kcmContainerArgs); argsCount != 1 {
+		return nil, fmt.Errorf("expected only one container argument, got %d", argsCount)
 	}
-
-	cipherSuites, cipherSuitesFound, err := unstructured.NestedStringSlice(observedConfig, "servingInfo", "cipherSuites")
+	kcmScript := strings.TrimSpace(kcmContainerArgs[0])
+	kcmArgs, err := kubeControllerManagerArguments(ctx, secretsGetter, required.Namespace, kcmScript, operatorSpec)
 	if err != nil {
-		return nil, false, fmt.Errorf("couldn't get the servingInfo.cipherSuites config from observedConfig: %w", err)
+		return nil, err
 	}
-
-	minTLSVersion, minTLSVersionFound, err := unstructured.NestedString(observedConfig, "servingInfo", "minTLSVersion")
+	// refuse to roll out flags the kube-controller-manager would crashloop on
+	flagSchema, err := LoadFlagSchema()
 	if err != nil {
-		return nil, false, fmt.Errorf("couldn't get the servingInfo.minTLSVersion config from observedConfig: %w", err)
+		return nil, err
 	}
-
-	if cipherSuitesFound && len(cipherSuites) > 0 {
-		kcmContainerArgsWithLoglevel[0] += fmt.Sprintf(" --tls-cipher-suites=%s", strings.Join(cipherSuites, ","))
+	if err := flagSchema.Validate(kcmArgs); err != nil {
+		return nil, err
 	}
+	kcmContainerArgs[0] = renderKubeControllerManagerCommand(kcmScript, kcmArgs)
 
-	if minTLSVersionFound && len(minTLSVersion) > 0 {
-		kcmContainerArgsWithLoglevel[0] += fmt.Sprintf(" --tls-min-version=%s", minTLSVersion)
+	var observedConfig map[string]interface{}
+	if err := yaml.Unmarshal(operatorSpec.ObservedConfig.Raw, &observedConfig); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the observedConfig: %w", err)
 	}
 
-	kcmContainerArgsWithLoglevel[0] = strings.TrimSpace(kcmContainerArgsWithLoglevel[0])
-
 	proxyConfig, _, err := unstructured.NestedStringMap(observedConfig, "targetconfigcontroller", "proxy")
 	if err != nil {
-		return nil, false, fmt.Errorf("couldn't get the proxy config from observedConfig: %w", err)
+		return nil, fmt.Errorf("couldn't get the proxy config from observedConfig: %w", err)
 	}
 
 	proxyEnvVars := proxyMapToEnvVars(proxyConfig)
@@ -666,39 +807,174 @@ func managePod(ctx context.Context, configMapsGetter corev1client.ConfigMapsGett
 	configMap.Data["pod.yaml"] = resourceread.WritePodV1OrDie(required)
 	configMap.Data["forceRedeploymentReason"] = operatorSpec.ForceRedeploymentReason
 	configMap.Data["version"] = version.Get().String()
-	return resourceapply.ApplyConfigMap(ctx, configMapsGetter, recorder, configMap)
+	// a pod with unresolved images would never start, do not produce a revision for it
+	if err := checkRenderedConfigMap(configMap); err != nil {
+		return nil, err
+	}
+	return configMap, nil
+}
+
+// kubeControllerManagerArguments parses the flags of the kube-controller-manager container script and adds the flags
+// derived from the operator spec, the serving cert and the layers of the kube-controller-manager config. The flags are
+// not validated against the flag schema.
+func kubeControllerManagerArguments(ctx context.Context, secretsGetter corev1client.SecretsGetter, namespace, script string, operatorSpec *operatorv1.StaticPodOperatorSpec) (*Arguments, error) {
+	kcmArgs, err := parseKubeControllerManagerCommand(script)
+	if err != nil {
+		return nil, err
+	}
+
+	argErrs := []error{}
+	logging, err := containerLoggingConfig(operatorSpec)
+	if err != nil {
+		return nil, err
+	}
+	kcmLogging := logging["kube-controller-manager"]
+	if kcmLogging.Verbosity != nil {
+		if err := kcmArgs.Add(ArgumentSourceLogging, "v", fmt.Sprintf("%d", *kcmLogging.Verbosity)); err != nil {
+			argErrs = append(argErrs, err)
+		}
+	} else if err := kcmArgs.Add(ArgumentSourceLogLevel, "v", fmt.Sprintf("%d", logLevelToVerbosity(operatorSpec.LogLevel))); err != nil {
+		argErrs = append(argErrs, err)
+	}
+	if len(kcmLogging.VModule) > 0 {
+		if err := kcmArgs.Add(ArgumentSourceLogging, "vmodule", kcmLogging.VModule); err != nil {
+			argErrs = append(argErrs, err)
+		}
+	}
+
+	if _, err := secretsGetter.Secrets(namespace).Get(ctx, "serving-cert", metav1.GetOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	} else if err == nil {
+		if err := kcmArgs.Add(ArgumentSourceServingCert, "tls-cert-file", "/etc/kubernetes/static-pod-resources/secrets/serving-cert/tls.crt"); err != nil {
+			argErrs = append(argErrs, err)
+		}
+		if err := kcmArgs.Add(ArgumentSourceServingCert, "tls-private-key-file", "/etc/kubernetes/static-pod-resources/secrets/serving-cert/tls.key"); err != nil {
+			argErrs = append(argErrs, err)
+		}
+	}
+
+	var observedConfig map[string]interface{}
+	if err := yaml.Unmarshal(operatorSpec.ObservedConfig.Raw, &observedConfig); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the observedConfig: %w", err)
+	}
+
+	cipherSuites, cipherSuitesFound, err := unstructured.NestedStringSlice(observedConfig, "servingInfo", "cipherSuites")
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get the servingInfo.cipherSuites config from observedConfig: %w", err)
+	}
+
+	minTLSVersion, minTLSVersionFound, err := unstructured.NestedString(observedConfig, "servingInfo", "minTLSVersion")
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get the servingInfo.minTLSVersion config from observedConfig: %w", err)
+	}
+
+	if cipherSuitesFound && len(cipherSuites) > 0 {
+		if err := kcmArgs.Add(ArgumentSourceServingInfo, "tls-cipher-suites", strings.Join(cipherSuites, ",")); err != nil {
+			argErrs = append(argErrs, err)
+		}
+	}
+
+	if minTLSVersionFound && len(minTLSVersion) > 0 {
+		if err := kcmArgs.Add(ArgumentSourceServingInfo, "tls-min-version", minTLSVersion); err != nil {
+			argErrs = append(argErrs, err)
+		}
+	}
+
+	if err := addConfigExtendedArguments(kcmArgs, operatorSpec); err != nil {
+		argErrs = append(argErrs, err)
+	}
+
+	if len(argErrs) > 0 {
+		return nil, fmt.Errorf("invalid kube-controller-manager arguments: %w", utilerrors.NewAggregate(argErrs))
+	}
+
+	return kcmArgs, nil
 }
 
+// logLevelToVerbosity maps the operator log level to the klog verbosity of the operands.
+func logLevelToVerbosity(logLevel operatorv1.LogLevel) int {
+	switch logLevel {
+	case operatorv1.Normal:
+		return 2
+	case operatorv1.Debug:
+		return 4
+	case operatorv1.Trace:
+		return 6
+	case operatorv1.TraceAll:
+		return 10
+	default:
+		return 2
+	}
+}
+
+// GetKubeControllerManagerArgs returns the flags of the extendedArguments of a kube-controller-manager config sorted
+// like the flags of the operator rendered pod. Invalid extendedArguments are logged and left out.
 func GetKubeControllerManagerArgs(config map[string]interface{}) []string {
-	extendedArguments, ok := config["extendedArguments"]
-	if !ok || extendedArguments == nil {
+	args := NewArguments()
+	if err := addExtendedArguments(args, ArgumentSourceConfig, config); err != nil {
+		klog.Warningf("Ignoring invalid kube-controller-manager arguments: %v", err)
+	}
+	if len(args.List()) == 0 {
 		return nil
 	}
-	args := []string{}
-	for key, value := range extendedArguments.(map[string]interface{}) {
-		for _, arrayValue := range value.([]interface{}) {
-			args = append(args, fmt.Sprintf("--%s=%s", key, arrayValue.(string)))
-		}
+	return args.Strings()
+}
+
+// ValidateKubeControllerManagerArgs validates the extendedArguments of a kube-controller-manager config against the flag
+// schema like the flags of the operator rendered pod, so that the bootstrap kube-controller-manager is not started with
+// flags it would crashloop on.
+func ValidateKubeControllerManagerArgs(config map[string]interface{}) error {
+	args := NewArguments()
+	if err := addExtendedArguments(args, ArgumentSourceConfig, config); err != nil {
+		return fmt.Errorf("invalid kube-controller-manager arguments: %w", err)
+	}
+	flagSchema, err := LoadFlagSchema()
+	if err != nil {
+		return err
 	}
-	// make sure to sort the arguments, otherwise we might get mismatch
-	// when comparing revisions leading to new ones being created, unnecessarily
-	sort.Strings(args)
-	return args
+	return flagSchema.Validate(args)
 }
 
 func manageServiceAccountCABundle(ctx context.Context, lister corev1listers.ConfigMapLister, client corev1client.ConfigMapsGetter, recorder events.Recorder) (*corev1.ConfigMap, bool, error) {
+	caBundleConfigMap, requiredConfigMap, updateRequired, err := renderServiceAccountCABundle(lister)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if caBundleConfigMap == nil {
+		caBundleConfigMap, err = client.ConfigMaps(operatorclient.TargetNamespace).Create(ctx, requiredConfigMap, metav1.CreateOptions{})
+		resourcehelper.ReportCreateEvent(recorder, caBundleConfigMap, err)
+		if err != nil {
+			return nil, false, err
+		}
+		klog.V(2).Infof("Created serviceaccount CA bundle configmap %s/%s", caBundleConfigMap.Namespace, caBundleConfigMap.Name)
+		return caBundleConfigMap, true, nil
+	} else if updateRequired {
+		caBundleConfigMap, err = client.ConfigMaps(operatorclient.TargetNamespace).Update(ctx, requiredConfigMap, metav1.UpdateOptions{})
+		resourcehelper.ReportUpdateEvent(recorder, caBundleConfigMap, err)
+		if err != nil {
+			return nil, false, err
+		}
+		klog.V(2).Infof("Updated serviceaccount CA bundle configmap %s/%s", caBundleConfigMap.Namespace, caBundleConfigMap.Name)
+		return caBundleConfigMap, true, nil
+	}
+
+	return caBundleConfigMap, false, nil
+}
+
+// renderServiceAccountCABundle returns the existing serviceaccount-ca configmap, nil if it does not exist yet, and the
+// required one combined from the kube-apiserver and ingress CA bundles.
+func renderServiceAccountCABundle(lister corev1listers.ConfigMapLister) (*corev1.ConfigMap, *corev1.ConfigMap, bool, error) {
 	additionalAnnotations := certrotation.AdditionalAnnotations{
 		JiraComponent: "kube-controller-manager",
 	}
 	caBundleConfigMapName := "serviceaccount-ca"
 
-	creationRequired := false
-	updateRequired := false
-
-	caBundleConfigMap, err := lister.ConfigMaps(operatorclient.TargetNamespace).Get(caBundleConfigMapName)
+	existing, err := lister.ConfigMaps(operatorclient.TargetNamespace).Get(caBundleConfigMapName)
+	caBundleConfigMap := existing
 	switch {
 	case apierrors.IsNotFound(err):
-		creationRequired = true
+		existing = nil
 		caBundleConfigMap = &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      caBundleConfigMapName,
@@ -706,7 +982,7 @@ func manageServiceAccountCABundle(ctx context.Context, lister corev1listers.Conf
 			},
 		}
 	case err != nil:
-		return nil, false, err
+		return nil, nil, false, err
 	}
 
 	requiredConfigMap, updateRequired, err := resourcesynccontroller.CombineCABundleConfigMapsOptimistically(
@@ -720,28 +996,9 @@ func manageServiceAccountCABundle(ctx context.Context, lister corev1listers.Conf
 		resourcesynccontroller.ResourceLocation{Namespace: operatorclient.GlobalMachineSpecifiedConfigNamespace, Name: "default-ingress-cert"},
 	)
 	if err != nil {
-		return nil, false, err
+		return nil, nil, false, err
 	}
-
-	if creationRequired {
-		caBundleConfigMap, err = client.ConfigMaps(operatorclient.TargetNamespace).Create(ctx, requiredConfigMap, metav1.CreateOptions{})
-		resourcehelper.ReportCreateEvent(recorder, caBundleConfigMap, err)
-		if err != nil {
-			return nil, false, err
-		}
-		klog.V(2).Infof("Created serviceaccount CA bundle configmap %s/%s", caBundleConfigMap.Namespace, caBundleConfigMap.Name)
-		return caBundleConfigMap, true, nil
-	} else if updateRequired {
-		caBundleConfigMap, err = client.ConfigMaps(operatorclient.TargetNamespace).Update(ctx, requiredConfigMap, metav1.UpdateOptions{})
-		resourcehelper.ReportUpdateEvent(recorder, caBundleConfigMap, err)
-		if err != nil {
-			return nil, false, err
-		}
-		klog.V(2).Infof("Updated serviceaccount CA bundle configmap %s/%s", caBundleConfigMap.Namespace, caBundleConfigMap.Name)
-		return caBundleConfigMap, true, nil
-	}
-
-	return caBundleConfigMap, false, nil
+	return existing, requiredConfigMap, updateRequired, nil
 }
 
 func ManageCSRCABundle(ctx context.Context, lister corev1listers.ConfigMapLister, client corev1client.ConfigMapsGetter, recorder events.Recorder) (*corev1.ConfigMap, bool, error) {
@@ -803,7 +1060,7 @@ func ManageCSRCABundle(ctx context.Context, lister corev1listers.ConfigMapLister
 
 func ManageCSRSigner(ctx context.Context, lister corev1listers.SecretLister, client corev1client.SecretsGetter, recorder events.Recorder) (*corev1.Secret, time.Duration, bool, error) {
 	// get the certkey pair we will sign with. We're going to add the cert to a ca bundle so we can recognize the chain it signs back to the signer
-	csrSigner, err := lister.Secrets(operatorclient.OperatorNamespace).Get("csr-signer")
+	csrSigner, err := csrSignerSource(lister)
 	if apierrors.IsNotFound(err) {
 		return nil, 0, false, nil
 	}
@@ -816,6 +1073,10 @@ func ManageCSRSigner(ctx context.Context, lister corev1listers.SecretLister, cli
 	if certBytes == nil || signingKey == nil || err != nil {
 		return nil, 0, false, err
 	}
+	// a user supplied signer may have been issued long before it was handed to us
+	if acceptedAt, err := time.Parse(time.RFC3339, csrSigner.Annotations[csrSignerUseAfterAnnotation]); err == nil && acceptedAt.After(useAfter) {
+		useAfter = acceptedAt
+	}
 
 	// make sure we wait five minutes to propagate the change to other components, like kas for trust
 	useAfter = useAfter.Add(5 * time.Minute)
@@ -906,6 +1167,22 @@ func ManageCSRIntermediateCABundle(ctx context.Context, lister corev1listers.Sec
 	if err != nil {
 		return nil, false, err
 	}
+	signerCertificates := signingCertKeyPair.Config.Certs
+
+	// the managed signer is always trusted so that reverting to it is safe. Of a user supplied signer only the
+	// intermediate is trusted, not the enterprise CA it chains to.
+	userSigner, err := lister.Secrets(operatorclient.OperatorNamespace).Get(userCSRSignerName)
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return nil, false, err
+	default:
+		userCertKeyPair, err := crypto.GetCAFromBytes(userSigner.Data["tls.crt"], userSigner.Data["tls.key"])
+		if err != nil {
+			return nil, false, err
+		}
+		signerCertificates = append(signerCertificates, userCertKeyPair.Config.Certs[0])
+	}
 
 	csrSignerCA, err := client.ConfigMaps(operatorclient.OperatorNamespace).Get(ctx, "csr-signer-ca", metav1.GetOptions{})
 	if apierrors.IsNotFound(err) {
@@ -932,7 +1209,19 @@ func ManageCSRIntermediateCABundle(ctx context.Context, lister corev1listers.Sec
 			return nil, false, err
 		}
 	}
-	certificates = append(certificates, signingCertKeyPair.Config.Certs...)
+	certificates = append(certificates, signerCertificates...)
+
+	caBytes, err := CombineCABundle(certificates...)
+	if err != nil {
+		return nil, false, err
+	}
+	csrSignerCA.Data["ca-bundle.crt"] = string(caBytes)
+
+	return resourceapply.ApplyConfigMap(ctx, client, recorder, csrSignerCA)
+}
+
+// CombineCABundle returns the PEM bundle of the certificates without the expired ones and duplicates, in order.
+func CombineCABundle(certificates ...*x509.Certificate) ([]byte, error) {
 	certificates = crypto.FilterExpiredCerts(certificates...)
 
 	finalCertificates := []*x509.Certificate{}
@@ -950,13 +1239,7 @@ func ManageCSRIntermediateCABundle(ctx context.Context, lister corev1listers.Sec
 		}
 	}
 
-	caBytes, err := crypto.EncodeCertificates(finalCertificates...)
-	if err != nil {
-		return nil, false, err
-	}
-	csrSignerCA.Data["ca-bundle.crt"] = string(caBytes)
-
-	return resourceapply.ApplyConfigMap(ctx, client, recorder, csrSignerCA)
+	return crypto.EncodeCertificates(finalCertificates...)
 }
 
 func ensureKubeControllerManagerTrustedCA(ctx context.Context, client corev1client.CoreV1Interface, recorder events.Recorder) error {
@@ -0,0 +1,157 @@
+package targetconfigcontroller
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/api/annotations"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/operatorclient"
+)
+
+const (
+	// UserCSRSignerSecretName is the secret in openshift-config holding a user supplied intermediate CA to sign the
+	// kubelet and client certificates with, instead of the signer managed by the operator.
+	UserCSRSignerSecretName = "kube-controller-manager-csr-signer"
+
+	// userCSRSignerName is the validated copy of the user supplied signer in the operator namespace. ManageCSRSigner
+	// prefers it over the managed csr-signer.
+	userCSRSignerName = "csr-signer-user"
+
+	// csrSignerUseAfterAnnotation records when the operator accepted the user supplied signer. The signer is not used
+	// before it had time to be trusted, regardless of when the certificate was issued.
+	csrSignerUseAfterAnnotation = "kube-controller-manager.openshift.io/use-after"
+)
+
+// ManageUserCSRSigner validates the user supplied CSR signer and copies it to the operator namespace. Invalid signers
+// are rejected and the last valid one is kept. Removing the user supplied signer reverts to the managed one.
+func ManageUserCSRSigner(ctx context.Context, lister corev1listers.SecretLister, client corev1client.SecretsGetter, recorder events.Recorder, signingDuration time.Duration) (*corev1.Secret, bool, error) {
+	userSigner, err := lister.Secrets(operatorclient.GlobalUserSpecifiedConfigNamespace).Get(UserCSRSignerSecretName)
+	if apierrors.IsNotFound(err) {
+		if _, err := lister.Secrets(operatorclient.OperatorNamespace).Get(userCSRSignerName); apierrors.IsNotFound(err) {
+			return nil, false, nil
+		}
+		_, modified, err := resourceapply.DeleteSecret(ctx, client, recorder, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.OperatorNamespace, Name: userCSRSignerName}})
+		return nil, modified, err
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	certBytes, keyBytes := userSigner.Data[corev1.TLSCertKey], userSigner.Data[corev1.TLSPrivateKeyKey]
+	if err := validateCSRSigner(certBytes, keyBytes, signingDuration, time.Now()); err != nil {
+		return nil, false, fmt.Errorf("secret/%s in %s: %w", UserCSRSignerSecretName, operatorclient.GlobalUserSpecifiedConfigNamespace, err)
+	}
+
+	useAfter := time.Now().Format(time.RFC3339)
+	existing, err := lister.Secrets(operatorclient.OperatorNamespace).Get(userCSRSignerName)
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return nil, false, err
+	case string(existing.Data[corev1.TLSCertKey]) == string(certBytes) && len(existing.Annotations[csrSignerUseAfterAnnotation]) > 0:
+		// the same signer, keep when it was accepted
+		useAfter = existing.Annotations[csrSignerUseAfterAnnotation]
+	}
+
+	return resourceapply.ApplySecret(ctx, client, recorder, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: operatorclient.OperatorNamespace,
+			Name:      userCSRSignerName,
+			Annotations: map[string]string{
+				annotations.OpenShiftComponent: "kube-controller-manager",
+				csrSignerUseAfterAnnotation:    useAfter,
+			},
+		},
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       certBytes,
+			corev1.TLSPrivateKeyKey: keyBytes,
+		},
+		Type: corev1.SecretTypeTLS,
+	})
+}
+
+// validateCSRSigner checks that the first certificate is a CA allowed to issue kubelet serving and client certificates
+// for the whole cluster-signing-duration and that it matches the key.
+func validateCSRSigner(certBytes, keyBytes []byte, signingDuration time.Duration, now time.Time) error {
+	ca, err := crypto.GetCAFromBytes(certBytes, keyBytes)
+	if err != nil {
+		return fmt.Errorf("invalid certificate and key: %w", err)
+	}
+	signer := ca.Config.Certs[0]
+	if !signer.BasicConstraintsValid || !signer.IsCA {
+		return fmt.Errorf("certificate %q is not a CA", signer.Subject.CommonName)
+	}
+	if signer.KeyUsage&x509.KeyUsageCertSign == 0 {
+		return fmt.Errorf("certificate %q is missing the cert sign key usage", signer.Subject.CommonName)
+	}
+	if len(signer.ExtKeyUsage) > 0 {
+		serverAuth, clientAuth := false, false
+		for _, usage := range signer.ExtKeyUsage {
+			switch usage {
+			case x509.ExtKeyUsageAny:
+				serverAuth, clientAuth = true, true
+			case x509.ExtKeyUsageServerAuth:
+				serverAuth = true
+			case x509.ExtKeyUsageClientAuth:
+				clientAuth = true
+			}
+		}
+		if !serverAuth || !clientAuth {
+			return fmt.Errorf("certificate %q must allow the server auth and client auth extended key usages", signer.Subject.CommonName)
+		}
+	}
+	if now.Before(signer.NotBefore) {
+		return fmt.Errorf("certificate %q is not valid before %s", signer.Subject.CommonName, signer.NotBefore.Format(time.RFC3339))
+	}
+	if signer.NotAfter.Before(now.Add(signingDuration)) {
+		return fmt.Errorf("certificate %q expires at %s, before certificates signed now for the cluster-signing-duration of %s would", signer.Subject.CommonName, signer.NotAfter.Format(time.RFC3339), signingDuration)
+	}
+	return nil
+}
+
+// csrSignerSource returns the user supplied signer if there is one and the managed signer otherwise.
+func csrSignerSource(lister corev1listers.SecretLister) (*corev1.Secret, error) {
+	userSigner, err := lister.Secrets(operatorclient.OperatorNamespace).Get(userCSRSignerName)
+	if err == nil {
+		return userSigner, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	return lister.Secrets(operatorclient.OperatorNamespace).Get("csr-signer")
+}
+
+// clusterSigningDuration returns the cluster-signing-duration of the kube-controller-manager config.
+func clusterSigningDuration(operatorSpec *operatorv1.StaticPodOperatorSpec) (time.Duration, error) {
+	configMap, err := renderKubeControllerManagerConfig(operatorSpec)
+	if err != nil {
+		return 0, err
+	}
+	config := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(configMap.Data["config.yaml"]), &config); err != nil {
+		return 0, err
+	}
+	values, _, err := unstructured.NestedStringSlice(config, "extendedArguments", "cluster-signing-duration")
+	if err != nil {
+		return 0, err
+	}
+	if len(values) == 0 {
+		return 0, fmt.Errorf("cluster-signing-duration is not set")
+	}
+	return time.ParseDuration(values[len(values)-1])
+}
@@ -0,0 +1,233 @@
+package targetconfigcontroller
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/clock"
+
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-controller-manager-operator/pkg/operator/operatorclient"
+)
+
+func makeSigner(t *testing.T, mutate func(*x509.Certificate)) map[string][]byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "enterprise-intermediate"},
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	if mutate != nil {
+		mutate(template)
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certBytes, keyBytes, err := (&crypto.TLSCertificateConfig{Certs: []*x509.Certificate{cert}, Key: key}).GetPEMBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string][]byte{"tls.crt": certBytes, "tls.key": keyBytes}
+}
+
+func TestValidateCSRSigner(t *testing.T) {
+	otherKey := makeSigner(t, nil)["tls.key"]
+	tests := []struct {
+		name          string
+		signer        map[string][]byte
+		expectedError string
+	}{
+		{
+			name:   "valid",
+			signer: makeSigner(t, nil),
+		},
+		{
+			name: "valid with extended key usages",
+			signer: makeSigner(t, func(c *x509.Certificate) {
+				c.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+			}),
+		},
+		{
+			name:          "not a CA",
+			signer:        makeSigner(t, func(c *x509.Certificate) { c.IsCA = false }),
+			expectedError: "is not a CA",
+		},
+		{
+			name:          "no cert sign usage",
+			signer:        makeSigner(t, func(c *x509.Certificate) { c.KeyUsage = x509.KeyUsageDigitalSignature }),
+			expectedError: "missing the cert sign key usage",
+		},
+		{
+			name: "client auth only",
+			signer: makeSigner(t, func(c *x509.Certificate) {
+				c.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+			}),
+			expectedError: "must allow the server auth and client auth",
+		},
+		{
+			name:          "expires within the signing duration",
+			signer:        makeSigner(t, func(c *x509.Certificate) { c.NotAfter = time.Now().Add(10 * 24 * time.Hour) }),
+			expectedError: "before certificates signed now for the cluster-signing-duration of 720h0m0s would",
+		},
+		{
+			name:          "not valid yet",
+			signer:        makeSigner(t, func(c *x509.Certificate) { c.NotBefore = time.Now().Add(time.Hour) }),
+			expectedError: "is not valid before",
+		},
+		{
+			name:          "mismatching key",
+			signer:        map[string][]byte{"tls.crt": makeSigner(t, nil)["tls.crt"], "tls.key": otherKey},
+			expectedError: "invalid certificate and key",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateCSRSigner(test.signer["tls.crt"], test.signer["tls.key"], 720*time.Hour, time.Now())
+			switch {
+			case len(test.expectedError) == 0 && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case len(test.expectedError) > 0 && (err == nil || !strings.Contains(err.Error(), test.expectedError)):
+				t.Fatalf("expected error %q, got %v", test.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestManageUserCSRSigner(t *testing.T) {
+	userSigner := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.GlobalUserSpecifiedConfigNamespace, Name: UserCSRSignerSecretName},
+		Data:       makeSigner(t, nil),
+		Type:       corev1.SecretTypeTLS,
+	}
+	managedSigner := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.OperatorNamespace, Name: "csr-signer"},
+		Data:       makeCerts(t, time.Now().Add(-time.Hour), 24*time.Hour),
+		Type:       corev1.SecretTypeTLS,
+	}
+	recorder := events.NewInMemoryRecorder("test", clock.RealClock{})
+	client := fake.NewSimpleClientset(managedSigner)
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	lister := corev1listers.NewSecretLister(indexer)
+	for _, secret := range []*corev1.Secret{userSigner, managedSigner} {
+		if err := indexer.Add(secret); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the user supplied signer is accepted and preferred over the managed one, once it had time to be trusted
+	accepted, _, err := ManageUserCSRSigner(context.TODO(), lister, client.CoreV1(), recorder, 720*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(accepted.Data["tls.crt"]) != string(userSigner.Data["tls.crt"]) {
+		t.Fatal("expected the user supplied signer to be copied to the operator namespace")
+	}
+	if err := indexer.Add(accepted); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CoreV1().Secrets(operatorclient.TargetNamespace).Create(context.TODO(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.TargetNamespace, Name: "csr-signer"},
+		Data:       managedSigner.Data,
+	}, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	_, delay, changed, err := ManageCSRSigner(context.TODO(), lister, client.CoreV1(), recorder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed || delay < 5*time.Minute-10*time.Second {
+		t.Errorf("expected to wait for the user supplied signer to be trusted, got delay %v, changed %v", delay, changed)
+	}
+
+	// the same signer keeps the time it was accepted
+	accepted.Annotations[csrSignerUseAfterAnnotation] = time.Now().Add(-time.Hour).Format(time.RFC3339)
+	if err := indexer.Update(accepted); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CoreV1().Secrets(operatorclient.OperatorNamespace).Update(context.TODO(), accepted, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	reapplied, _, err := ManageUserCSRSigner(context.TODO(), lister, client.CoreV1(), recorder, 720*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reapplied.Annotations[csrSignerUseAfterAnnotation] != accepted.Annotations[csrSignerUseAfterAnnotation] {
+		t.Errorf("expected the accepted time to be kept, got %q", reapplied.Annotations[csrSignerUseAfterAnnotation])
+	}
+	target, _, changed, err := ManageCSRSigner(context.TODO(), lister, client.CoreV1(), recorder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed || string(target.Data["tls.crt"]) != string(userSigner.Data["tls.crt"]) {
+		t.Errorf("expected the user supplied signer to be used")
+	}
+
+	// both signers are trusted
+	bundle, _, err := ManageCSRIntermediateCABundle(context.TODO(), lister, client.CoreV1(), recorder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, signer := range []*corev1.Secret{userSigner, managedSigner} {
+		if !strings.Contains(bundle.Data["ca-bundle.crt"], string(signer.Data["tls.crt"])) {
+			t.Errorf("expected secret/%s to be trusted", signer.Name)
+		}
+	}
+
+	// an invalid signer is rejected and the accepted one is kept
+	invalid := userSigner.DeepCopy()
+	invalid.Data = makeSigner(t, func(c *x509.Certificate) { c.IsCA = false })
+	if err := indexer.Update(invalid); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ManageUserCSRSigner(context.TODO(), lister, client.CoreV1(), recorder, 720*time.Hour); err == nil || !strings.Contains(err.Error(), "is not a CA") {
+		t.Errorf("expected the invalid signer to be rejected, got %v", err)
+	}
+	if _, err := client.CoreV1().Secrets(operatorclient.OperatorNamespace).Get(context.TODO(), userCSRSignerName, metav1.GetOptions{}); err != nil {
+		t.Errorf("expected the accepted signer to be kept: %v", err)
+	}
+
+	// removing the user supplied signer reverts to the managed one
+	if err := indexer.Delete(invalid); err != nil {
+		t.Fatal(err)
+	}
+	if _, changed, err := ManageUserCSRSigner(context.TODO(), lister, client.CoreV1(), recorder, 720*time.Hour); err != nil || !changed {
+		t.Fatalf("expected the accepted signer to be removed, got changed %v, err %v", changed, err)
+	}
+	if err := indexer.Delete(reapplied); err != nil {
+		t.Fatal(err)
+	}
+	target, _, changed, err = ManageCSRSigner(context.TODO(), lister, client.CoreV1(), recorder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed || string(target.Data["tls.crt"]) != string(managedSigner.Data["tls.crt"]) {
+		t.Errorf("expected to revert to the managed signer")
+	}
+}